@@ -17,6 +17,7 @@ func main() {
 	// Taking input from user
 	fmt.Scanln(&roomid)
 	controller.Initialize()
+	controller.StartScheduler()
 	controller.SetDanmuClient(roomid)
 	ch := make(chan int)
 	<-ch
@@ -5,21 +5,24 @@ import (
 	"AynaLivePlayer/controller"
 	"AynaLivePlayer/gui"
 	"AynaLivePlayer/logger"
+	"AynaLivePlayer/plugin/blacklist"
 	"AynaLivePlayer/plugin/diange"
 	"AynaLivePlayer/plugin/qiege"
+	"AynaLivePlayer/plugin/queuecmd"
 	"AynaLivePlayer/plugin/textinfo"
 	"AynaLivePlayer/plugin/webinfo"
 	"AynaLivePlayer/plugin/wylogin"
 )
 
 var plugins = []controller.Plugin{diange.NewDiange(), qiege.NewQiege(), textinfo.NewTextInfo(), webinfo.NewWebInfo(),
-	wylogin.NewWYLogin()}
+	wylogin.NewWYLogin(), blacklist.NewBlacklist(), queuecmd.NewQueueCmd()}
 
 func main() {
 	logger.Logger.Info("================Program Start================")
 	logger.Logger.Infof("================Current Version: %s================", config.Version)
 	controller.Initialize()
 	controller.LoadPlugins(plugins...)
+	controller.StartScheduler()
 	gui.Initialize()
 	gui.MainWindow.ShowAndRun()
 	controller.ClosePlugins(plugins...)
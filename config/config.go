@@ -43,11 +43,40 @@ func init() {
 		fmt.Println("config not found, using default config")
 		ConfigFile = ini.Empty()
 	}
-	for _, cfg := range []Config{Log, LiveRoom, Player, Provider, General} {
+	for _, cfg := range []Config{Log, LiveRoom, Player, Provider, General, Schedule, Points, Role} {
 		LoadConfig(cfg)
 	}
 }
 
+// LegacyPermission is the permission of plugins before roles were introduced
+type LegacyPermission struct {
+	User      bool
+	Privilege bool
+	Admin     bool
+}
+
+// LoadLegacyPermission read UserPermission, PrivilegePermission and AdminPermission of the section,
+// ok is false if the section has been migrated (has key Roles) or has none of these keys.
+func LoadLegacyPermission(name string) (perm LegacyPermission, ok bool) {
+	sec, err := ConfigFile.GetSection(name)
+	if err != nil || sec.HasKey("Roles") {
+		return perm, false
+	}
+	// missing keys use the old default value
+	perm = LegacyPermission{User: true, Privilege: true, Admin: true}
+	for key, value := range map[string]*bool{
+		"UserPermission":      &perm.User,
+		"PrivilegePermission": &perm.Privilege,
+		"AdminPermission":     &perm.Admin,
+	} {
+		if sec.HasKey(key) {
+			ok = true
+			*value = sec.Key(key).MustBool(true)
+		}
+	}
+	return perm, ok
+}
+
 func SaveToConfigFile(filename string) error {
 	cfgFile := ini.Empty()
 	for _, cfg := range Configs {
@@ -1,11 +1,34 @@
 package config
 
 type _LiveRoomConfig struct {
-	History []string
+	History []string `audit:"-"`
+	// bilibili account used to send messages, BilibiliSessData and BilibiliJct are cookies SESSDATA and bili_jct
+	BilibiliUid      int
+	BilibiliSessData string `audit:"secret"`
+	BilibiliJct      string `audit:"secret"`
+	// twitch account used to send messages, TwitchToken is oauth token with chat scopes,
+	// login is anonymous and read only if empty
+	TwitchUsername string
+	TwitchToken    string `audit:"secret"`
+	// SendReply enable replying command results in live room
+	SendReply bool
+	// MessageInterval is minimum interval between messages in milliseconds
+	MessageInterval  int
+	MessageMaxLength int
 }
 
 func (c *_LiveRoomConfig) Name() string {
 	return "LiveRoom"
 }
 
-var LiveRoom = &_LiveRoomConfig{History: []string{"9076804", "3819533"}}
+var LiveRoom = &_LiveRoomConfig{
+	History:          []string{"9076804", "3819533"},
+	BilibiliUid:      0,
+	BilibiliSessData: "",
+	BilibiliJct:      "",
+	TwitchUsername:   "",
+	TwitchToken:      "",
+	SendReply:        false,
+	MessageInterval:  1500,
+	MessageMaxLength: 20,
+}
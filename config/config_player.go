@@ -3,11 +3,16 @@ package config
 type _PlayerConfig struct {
 	Playlists         []string
 	PlaylistsProvider []string
+	PlaylistsWeight   []int
 	PlaylistIndex     int
 	PlaylistRandom    bool
 	AudioDevice       string
 	Volume            float64
 	SkipPlaylist      bool
+	PrefetchCount     int
+	PrefetchUrlTTL    int
+	Autoplay          bool
+	AutoplaySeeds     int
 }
 
 func (c *_PlayerConfig) Name() string {
@@ -17,9 +22,14 @@ func (c *_PlayerConfig) Name() string {
 var Player = &_PlayerConfig{
 	Playlists:         []string{"2382819181", "4987059624", "list1"},
 	PlaylistsProvider: []string{"netease", "netease", "local"},
+	PlaylistsWeight:   []int{},
 	PlaylistIndex:     0,
 	PlaylistRandom:    true,
 	AudioDevice:       "auto",
 	Volume:            100,
 	SkipPlaylist:      false,
+	PrefetchCount:     2,
+	PrefetchUrlTTL:    600,
+	Autoplay:          false,
+	AutoplaySeeds:     5,
 }
@@ -0,0 +1,33 @@
+package config
+
+type _PointsConfig struct {
+	Enable bool
+	// ChatPoints is earned by chatting, at most once in ChatInterval seconds.
+	// guards get extra GuardChatBonus points.
+	ChatPoints     int
+	ChatInterval   int
+	GuardChatBonus int
+	// PointsPerYuan is earned by gifts and guard purchases
+	PointsPerYuan int
+	// PriceCommands and Prices are prices of commands by command name
+	PriceCommands  []string
+	Prices         []int
+	ReplyBalance   string
+	ReplyNotEnough string
+}
+
+func (c *_PointsConfig) Name() string {
+	return "Points"
+}
+
+var Points = &_PointsConfig{
+	Enable:         false,
+	ChatPoints:     1,
+	ChatInterval:   60,
+	GuardChatBonus: 1,
+	PointsPerYuan:  10,
+	PriceCommands:  []string{"积分插队"},
+	Prices:         []int{50},
+	ReplyBalance:   "{user} 积分: {balance}",
+	ReplyNotEnough: "{user} 积分不足, 需要{price}",
+}
@@ -1,8 +1,11 @@
 package config
 
 type _ProviderConfig struct {
-	Priority []string
-	LocalDir string
+	Priority          []string
+	LocalDir          string
+	Fallback          bool
+	FallbackThreshold float64
+	SearchTimeout     int
 }
 
 func (c *_ProviderConfig) Name() string {
@@ -10,6 +13,9 @@ func (c *_ProviderConfig) Name() string {
 }
 
 var Provider = &_ProviderConfig{
-	Priority: []string{"netease", "kuwo", "bilibili", "local", "bilibili-video"},
-	LocalDir: "./music",
+	Priority:          []string{"netease", "kuwo", "bilibili", "local", "bilibili-video"},
+	LocalDir:          "./music",
+	Fallback:          true,
+	FallbackThreshold: 0.75,
+	SearchTimeout:     5,
 }
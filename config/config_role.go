@@ -0,0 +1,30 @@
+package config
+
+// _RoleConfig store custom roles in parallel slices, role i is
+// (Names[i], MedalNames[i], MedalLevels[i], GuardLevels[i], Admins[i], Uids[i]).
+// a user has the role if any rule of the role matches.
+type _RoleConfig struct {
+	Names []string
+	// MedalNames and MedalLevels require the fan medal worn by user,
+	// empty name means any medal, 0 level means the rule is disabled if name is empty.
+	MedalNames  []string
+	MedalLevels []int
+	// GuardLevels is the lowest guard level (3 captain, 2 admiral, 1 governor), 0 means disabled
+	GuardLevels []int
+	Admins      []bool
+	// Uids are whitelists of uid separated by comma
+	Uids []string `delim:"|"`
+}
+
+func (c *_RoleConfig) Name() string {
+	return "Role"
+}
+
+var Role = &_RoleConfig{
+	Names:       []string{"medal"},
+	MedalNames:  []string{""},
+	MedalLevels: []int{10},
+	GuardLevels: []int{0},
+	Admins:      []bool{false},
+	Uids:        []string{""},
+}
@@ -0,0 +1,26 @@
+package config
+
+// _ScheduleConfig store schedule rules in parallel slices, rule i is
+// (Days[i], Times[i], Actions[i], Values[i]).
+type _ScheduleConfig struct {
+	Enable bool
+	// Days are strings of weekdays from 1 (Monday) to 7 (Sunday), e.g. 12345 means weekdays
+	Days []string
+	// Times are time of day in HH:MM
+	Times   []string
+	Actions []string
+	// Values may contain comma, so use another delimiter
+	Values []string `delim:"|"`
+}
+
+func (c *_ScheduleConfig) Name() string {
+	return "Schedule"
+}
+
+var Schedule = &_ScheduleConfig{
+	Enable:  false,
+	Days:    []string{},
+	Times:   []string{},
+	Actions: []string{},
+	Values:  []string{},
+}
@@ -13,3 +13,22 @@ func TestLoad(t *testing.T) {
 	fmt.Println(Log.Path)
 	fmt.Println(Player.Playlists)
 }
+
+func TestLoadLegacyPermission(t *testing.T) {
+	sec := ConfigFile.Section("LegacyTest")
+	sec.Key("UserPermission").SetValue("false")
+	sec.Key("AdminPermission").SetValue("true")
+	perm, ok := LoadLegacyPermission("LegacyTest")
+	fmt.Println(perm, ok)
+	if !ok || perm.User || !perm.Privilege || !perm.Admin {
+		t.Fatal("legacy permission is not loaded")
+	}
+	sec.Key("Roles").SetValue("user")
+	if _, ok = LoadLegacyPermission("LegacyTest"); ok {
+		t.Fatal("migrated section should be ignored")
+	}
+	if _, ok = LoadLegacyPermission("NotExist"); ok {
+		t.Fatal("missing section should be ignored")
+	}
+	ConfigFile.DeleteSection("LegacyTest")
+}
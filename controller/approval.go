@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"AynaLivePlayer/player"
+	"sync"
+	"time"
+)
+
+// PendingPlaylist hold requests waiting for moderator approval
+var PendingPlaylist *player.Playlist
+
+var pendingSince = make(map[*player.Media]time.Time)
+var pendingLock sync.Mutex
+
+// AddPending find the media like AddWithPriority, but put it into PendingPlaylist
+// instead of UserPlaylist. empty pname means searching all providers.
+func AddPending(keyword string, pname string, user interface{}, priority int) (*player.Media, error) {
+	media, err := ResolveRequest(keyword, pname, user)
+	if err != nil {
+		return nil, err
+	}
+	AddPendingRequest(media, priority)
+	return media, nil
+}
+
+// AddPendingRequest add media which has passed CheckRequest to PendingPlaylist
+func AddPendingRequest(media *player.Media, priority int) {
+	media.Priority = priority
+	l().Infof("add media %s (%s) to pending list", media.Title, media.Artist)
+	Audit(requestActor(media.User), AuditQueueAdd, "%s - %s (pending)", media.Title, media.Artist)
+	pendingLock.Lock()
+	pendingSince[media] = time.Now()
+	pendingLock.Unlock()
+	PendingPlaylist.Insert(-1, media)
+}
+
+// takePending remove media at index from PendingPlaylist and return it, nil if not exists.
+func takePending(index int) *player.Media {
+	pendingLock.Lock()
+	defer pendingLock.Unlock()
+	media := PendingPlaylist.Remove(index)
+	if media == nil {
+		l().Warnf("pending media at index %d does not exist", index)
+		return nil
+	}
+	delete(pendingSince, media)
+	return media
+}
+
+// takePendingMedia remove the media from PendingPlaylist, return false if it is not pending.
+func takePendingMedia(media *player.Media) bool {
+	pendingLock.Lock()
+	defer pendingLock.Unlock()
+	delete(pendingSince, media)
+	return PendingPlaylist.DeleteMedia(media)
+}
+
+// Approve move pending media at index to UserPlaylist, approver is recorded on the media.
+func Approve(index int, approver AuditActor) *player.Media {
+	media := takePending(index)
+	if media == nil {
+		return nil
+	}
+	approve(media, approver)
+	return media
+}
+
+// ApproveMedia approve the pending media, return false if it is no longer pending.
+func ApproveMedia(media *player.Media, approver AuditActor) bool {
+	if !takePendingMedia(media) {
+		l().Warnf("media %s is no longer pending", media.Title)
+		return false
+	}
+	approve(media, approver)
+	return true
+}
+
+func approve(media *player.Media, approver AuditActor) {
+	l().Infof("%s approve media %s (%s)", approver, media.Title, media.Artist)
+	Audit(approver, AuditApprove, "%s - %s", media.Title, media.Artist)
+	media.Approver = approver.String()
+	insertRequest(media)
+}
+
+// Reject remove pending media at index.
+func Reject(index int, approver AuditActor) *player.Media {
+	media := takePending(index)
+	if media == nil {
+		return nil
+	}
+	reject(media, approver)
+	return media
+}
+
+// RejectMedia reject the pending media, return false if it is no longer pending.
+func RejectMedia(media *player.Media, approver AuditActor) bool {
+	if !takePendingMedia(media) {
+		l().Warnf("media %s is no longer pending", media.Title)
+		return false
+	}
+	reject(media, approver)
+	return true
+}
+
+func reject(media *player.Media, approver AuditActor) {
+	l().Infof("%s reject media %s (%s)", approver, media.Title, media.Artist)
+	Audit(approver, AuditReject, "%s - %s", media.Title, media.Artist)
+}
+
+// ExpirePending remove pending medias which have been waiting longer than timeout,
+// return number of removed medias.
+func ExpirePending(timeout time.Duration) int {
+	pendingLock.Lock()
+	defer pendingLock.Unlock()
+	PendingPlaylist.Lock.RLock()
+	expired := make([]*player.Media, 0)
+	for _, m := range PendingPlaylist.Playlist {
+		if t, ok := pendingSince[m]; ok && time.Since(t) > timeout {
+			expired = append(expired, m)
+		}
+	}
+	PendingPlaylist.Lock.RUnlock()
+	// playlist might be changed after unlock, so medias are deleted by identity
+	cnt := 0
+	for _, m := range expired {
+		delete(pendingSince, m)
+		if !PendingPlaylist.DeleteMedia(m) {
+			continue
+		}
+		cnt++
+		l().Infof("pending media %s expired", m.Title)
+		Audit(ActorSystem, AuditReject, "%s - %s (expired)", m.Title, m.Artist)
+	}
+	return cnt
+}
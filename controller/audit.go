@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/util"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditPath is the moderation audit log, one json record per line
+const AuditPath = "./audit.jsonl"
+
+const EventAuditUpdate event.EventId = "controller.audit.update"
+
+type AuditUpdateEvent struct {
+	Record *AuditRecord
+}
+
+type AuditActorType string
+
+const (
+	AuditActorGUI    AuditActorType = "gui"
+	AuditActorDanmu  AuditActorType = "danmu"
+	AuditActorWeb    AuditActorType = "web"
+	AuditActorSystem AuditActorType = "system"
+)
+
+var AuditActorTypes = []AuditActorType{AuditActorGUI, AuditActorDanmu, AuditActorWeb, AuditActorSystem}
+
+// AuditActor is who did the operation, Id is uid for danmu and remote address for web
+type AuditActor struct {
+	Type AuditActorType
+	Id   string
+	Name string
+}
+
+var (
+	ActorGUI    = AuditActor{Type: AuditActorGUI, Name: "GUI"}
+	ActorSystem = AuditActor{Type: AuditActorSystem, Name: "System"}
+)
+
+func DanmuActor(user *liveclient.DanmuUser) AuditActor {
+	return AuditActor{Type: AuditActorDanmu, Id: user.Uid, Name: user.Username}
+}
+
+func WebActor(addr string) AuditActor {
+	return AuditActor{Type: AuditActorWeb, Id: addr, Name: addr}
+}
+
+// requestActor return actor of the requester of media
+func requestActor(user interface{}) AuditActor {
+	switch u := user.(type) {
+	case *liveclient.DanmuUser:
+		return DanmuActor(u)
+	case *player.User:
+		if u == player.SystemUser {
+			return ActorSystem
+		}
+	}
+	return ActorGUI
+}
+
+func (a AuditActor) String() string {
+	if a.Id == "" || a.Id == a.Name {
+		return a.Name
+	}
+	return fmt.Sprintf("%s(%s)", a.Name, a.Id)
+}
+
+type AuditAction string
+
+const (
+	AuditQueueAdd    AuditAction = "queue.add"
+	AuditQueueDelete AuditAction = "queue.delete"
+	AuditQueueMove   AuditAction = "queue.move"
+	AuditApprove     AuditAction = "queue.approve"
+	AuditReject      AuditAction = "queue.reject"
+	AuditSkip        AuditAction = "skip"
+	AuditBan         AuditAction = "ban"
+	AuditUnban       AuditAction = "unban"
+	AuditVolume      AuditAction = "volume"
+	AuditConfig      AuditAction = "config"
+)
+
+var AuditActions = []AuditAction{
+	AuditQueueAdd, AuditQueueDelete, AuditQueueMove, AuditApprove, AuditReject,
+	AuditSkip, AuditBan, AuditUnban, AuditVolume, AuditConfig,
+}
+
+type AuditRecord struct {
+	Time   int64
+	Actor  AuditActor
+	Action AuditAction
+	Detail string
+}
+
+func (r *AuditRecord) String() string {
+	return fmt.Sprintf("%s [%s] %s %s: %s",
+		time.Unix(r.Time, 0).Format("2006-01-02 15:04:05"), r.Actor.Type, r.Actor, r.Action, r.Detail)
+}
+
+// AuditFilter is used to query audit records, empty field matches all
+type AuditFilter struct {
+	ActorType AuditActorType
+	Action    AuditAction
+	// Keyword matches actor name or actor id exactly, or part of detail
+	Keyword string
+	Since   int64
+	Until   int64
+}
+
+func (f *AuditFilter) Match(r *AuditRecord) bool {
+	if f.Since > 0 && r.Time < f.Since {
+		return false
+	}
+	if f.Until > 0 && r.Time >= f.Until {
+		return false
+	}
+	if f.ActorType != "" && r.Actor.Type != f.ActorType {
+		return false
+	}
+	if f.Action != "" && r.Action != f.Action {
+		return false
+	}
+	return f.Keyword == "" || strings.EqualFold(r.Actor.Name, f.Keyword) ||
+		r.Actor.Id == f.Keyword || containsFold(r.Detail, f.Keyword)
+}
+
+type AuditStore struct {
+	Records  []*AuditRecord
+	Handler  *event.Handler
+	filename string
+	lock     sync.RWMutex
+}
+
+var AuditLog *AuditStore
+
+func NewAuditStore(filename string) *AuditStore {
+	s := &AuditStore{
+		Records:  make([]*AuditRecord, 0),
+		Handler:  event.NewHandler(),
+		filename: filename,
+	}
+	err := util.LoadJsonLines(filename, func(line []byte) {
+		var r AuditRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			l().Warnf("skip invalid audit record: %s", err)
+			return
+		}
+		s.Records = append(s.Records, &r)
+	})
+	if err != nil {
+		l().Infof("load audit log from %s failed: %s", filename, err)
+	}
+	return s
+}
+
+func (s *AuditStore) Add(record *AuditRecord) {
+	s.lock.Lock()
+	s.Records = append(s.Records, record)
+	if err := util.AppendJsonLine(s.filename, record); err != nil {
+		l().Warnf("write audit record to %s failed: %s", s.filename, err)
+	}
+	s.lock.Unlock()
+	s.Handler.CallA(EventAuditUpdate, AuditUpdateEvent{Record: record})
+}
+
+// Query return records matching filter, latest first
+func (s *AuditStore) Query(filter AuditFilter) []*AuditRecord {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	result := make([]*AuditRecord, 0)
+	for i := len(s.Records) - 1; i >= 0; i-- {
+		if filter.Match(s.Records[i]) {
+			result = append(result, s.Records[i])
+		}
+	}
+	return result
+}
+
+// Audit record an operation done by actor, detail is formatted like fmt.Sprintf
+func Audit(actor AuditActor, action AuditAction, format string, args ...interface{}) {
+	r := &AuditRecord{
+		Time:   time.Now().Unix(),
+		Actor:  actor,
+		Action: action,
+		Detail: fmt.Sprintf(format, args...),
+	}
+	l().Infof("audit: %s %s %s", r.Actor, r.Action, r.Detail)
+	if AuditLog == nil {
+		return
+	}
+	AuditLog.Add(r)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"AynaLivePlayer/liveclient"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditStore_Query(t *testing.T) {
+	filename := filepath.Join(os.TempDir(), "audit_test.jsonl")
+	defer os.Remove(filename)
+	_ = os.Remove(filename)
+	AuditLog = NewAuditStore(filename)
+	defer func() { AuditLog = nil }()
+	Audit(ActorGUI, AuditSkip, "%s - %s", "晴天", "周杰伦")
+	Audit(DanmuActor(&liveclient.DanmuUser{Uid: "123", Username: "user"}), AuditQueueDelete, "稻香 - 周杰伦 at %d", 2)
+	Audit(WebActor("127.0.0.1:1234"), AuditConfig, "webinfo template default")
+	s := NewAuditStore(filename)
+	if len(s.Records) != 3 {
+		t.Fatal("audit records should be restored from file")
+	}
+	if r := s.Query(AuditFilter{Keyword: "123"}); len(r) != 1 || r[0].Action != AuditQueueDelete {
+		t.Fatal("actor uid should match")
+	}
+	if r := s.Query(AuditFilter{Keyword: "USER"}); len(r) != 1 || r[0].Actor.Id != "123" {
+		t.Fatal("actor name should match")
+	}
+	if len(s.Query(AuditFilter{ActorType: AuditActorGUI, Keyword: "晴天"})) != 1 ||
+		len(s.Query(AuditFilter{Action: AuditBan})) != 0 {
+		t.Fatal("filter does not match")
+	}
+}
+
+type testAuditConfig struct {
+	Volume  int
+	Token   string   `audit:"secret"`
+	History []string `audit:"-"`
+	hidden  int
+}
+
+func (c *testAuditConfig) Name() string {
+	return "Test"
+}
+
+func TestConfigAuditor(t *testing.T) {
+	filename := filepath.Join(os.TempDir(), "audit_config_test.jsonl")
+	defer os.Remove(filename)
+	_ = os.Remove(filename)
+	AuditLog = NewAuditStore(filename)
+	defer func() { AuditLog = nil }()
+	cfg := &testAuditConfig{Volume: 50}
+	a := NewConfigAuditor(ActorGUI, cfg)
+	cfg.History = []string{"1"}
+	cfg.hidden = 1
+	a.Flush()
+	if len(AuditLog.Records) != 0 {
+		t.Fatal("ignored fields should not be recorded")
+	}
+	cfg.Volume = 60
+	cfg.Token = "secret-token"
+	a.Flush()
+	a.Flush()
+	if len(AuditLog.Records) != 1 {
+		t.Fatal("changes should be recorded once")
+	}
+	r := AuditLog.Records[0]
+	if r.Action != AuditConfig || r.Detail != "Test Volume: 50 -> 60, Token changed" {
+		t.Fatal("changes are not recorded correctly")
+	}
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// autoplayHistorySize is the number of recent history records which won't be recommended again
+const autoplayHistorySize = 200
+
+var autoplayQueue = make([]*player.Media, 0)
+var autoplayLock sync.Mutex
+
+func autoplayKey(pname, id, title, artist string) string {
+	if pname != "" && id != "" {
+		return pname + ":" + id
+	}
+	return strings.ToLower(title + "|" + artist)
+}
+
+func mediaAutoplayKey(media *player.Media) string {
+	meta, _ := media.Meta.(provider.Meta)
+	return autoplayKey(meta.Name, meta.Id, media.Title, media.Artist)
+}
+
+// autoplaySeeds return recent played medias as seeds and keys of recent history
+func autoplaySeeds() ([]*player.Media, map[string]bool) {
+	played := make(map[string]bool)
+	seeds := make([]*player.Media, 0)
+	records := Stats.history()
+	for i := 0; i < len(records) && i < autoplayHistorySize; i++ {
+		r := records[i]
+		key := autoplayKey(r.Provider, r.Id, r.Title, r.Artist)
+		if played[key] {
+			continue
+		}
+		played[key] = true
+		if len(seeds) < config.Player.AutoplaySeeds && r.Provider != "" {
+			seeds = append(seeds, r.ToMedia())
+		}
+	}
+	return seeds, played
+}
+
+// refillAutoplay fill the autoplay queue with medias similar to recent played medias,
+// medias played recently or in blacklist are filtered.
+func refillAutoplay() {
+	seeds, played := autoplaySeeds()
+	l().Infof("refill autoplay queue with %d seeds", len(seeds))
+	candidates := make([][]*player.Media, 0)
+	for _, seed := range seeds {
+		medias, err := provider.Recommend(seed)
+		if err != nil {
+			l().Debugf("get recommendation of %s failed: %s", seed.Title, err)
+			continue
+		}
+		rand.Shuffle(len(medias), func(i, j int) {
+			medias[i], medias[j] = medias[j], medias[i]
+		})
+		candidates = append(candidates, medias)
+	}
+	// take medias from every seed in turn, so the queue is not dominated by one seed
+	for i := 0; len(candidates) > 0; i++ {
+		remain := make([][]*player.Media, 0)
+		for _, medias := range candidates {
+			if i >= len(medias) {
+				continue
+			}
+			remain = append(remain, medias)
+			m := medias[i]
+			key := mediaAutoplayKey(m)
+			if played[key] || Blacklist.CheckMedia(m) != nil {
+				continue
+			}
+			played[key] = true
+			m.User = player.AutoplayUser
+			autoplayQueue = append(autoplayQueue, m)
+		}
+		candidates = remain
+	}
+	l().Infof("autoplay queue has %d medias", len(autoplayQueue))
+}
+
+// handleAutoplayRequest drop the recommendations when a new request arrives,
+// so next time the recommendations are based on the new request.
+func handleAutoplayRequest(event *event.Event) {
+	autoplayLock.Lock()
+	autoplayQueue = make([]*player.Media, 0)
+	autoplayLock.Unlock()
+}
+
+// nextAutoplayMedia return the next recommended media, nil if there is no recommendation
+func nextAutoplayMedia() *player.Media {
+	autoplayLock.Lock()
+	defer autoplayLock.Unlock()
+	if len(autoplayQueue) == 0 {
+		refillAutoplay()
+	}
+	if len(autoplayQueue) == 0 {
+		return nil
+	}
+	m := autoplayQueue[0]
+	autoplayQueue = autoplayQueue[1:]
+	return m
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
+	"AynaLivePlayer/util"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const BlacklistPath = "./blacklist.json"
+
+type BlacklistRuleType string
+
+const (
+	// BlacklistSong value is provider name and media id joined by colon, e.g. netease:12345
+	BlacklistSong    BlacklistRuleType = "song"
+	BlacklistArtist  BlacklistRuleType = "artist"
+	BlacklistKeyword BlacklistRuleType = "keyword"
+	BlacklistRegex   BlacklistRuleType = "regex"
+	BlacklistUser    BlacklistRuleType = "user"
+)
+
+var BlacklistRuleTypes = []BlacklistRuleType{
+	BlacklistSong, BlacklistArtist, BlacklistKeyword, BlacklistRegex, BlacklistUser,
+}
+
+type BlacklistRule struct {
+	Type  BlacklistRuleType
+	Value string
+	// Note is a human-readable description, like song title or username
+	Note string
+}
+
+func (r *BlacklistRule) String() string {
+	if r.Note == "" {
+		return fmt.Sprintf("%s: %s", r.Type, r.Value)
+	}
+	return fmt.Sprintf("%s: %s (%s)", r.Type, r.Value, r.Note)
+}
+
+// BlacklistError is returned when a request is rejected by blacklist
+type BlacklistError struct {
+	Rule *BlacklistRule
+}
+
+func (e *BlacklistError) Error() string {
+	return "rejected by blacklist rule " + e.Rule.String()
+}
+
+type BlacklistStore struct {
+	Rules    []*BlacklistRule
+	filename string
+	regex    map[string]*regexp.Regexp
+	lock     sync.RWMutex
+}
+
+var Blacklist *BlacklistStore
+
+func NewBlacklistStore(filename string) *BlacklistStore {
+	b := &BlacklistStore{
+		Rules:    make([]*BlacklistRule, 0),
+		filename: filename,
+		regex:    make(map[string]*regexp.Regexp),
+	}
+	if err := util.LoadJson(filename, &b.Rules); err != nil {
+		l().Infof("load blacklist from %s failed: %s", filename, err)
+	}
+	return b
+}
+
+func BlacklistSongValue(media *player.Media) string {
+	meta, ok := media.Meta.(provider.Meta)
+	if !ok {
+		return ""
+	}
+	return meta.Name + ":" + meta.Id
+}
+
+func (b *BlacklistStore) Save() {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if err := util.SaveJson(b.filename, b.Rules); err != nil {
+		l().Warnf("save blacklist to %s failed: %s", b.filename, err)
+	}
+}
+
+func (b *BlacklistStore) Size() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return len(b.Rules)
+}
+
+func (b *BlacklistStore) Get(index int) *BlacklistRule {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if index < 0 || index >= len(b.Rules) {
+		return nil
+	}
+	return b.Rules[index]
+}
+
+// Add a new rule to blacklist and save to disk, return false if rule is invalid or already exists.
+func (b *BlacklistStore) Add(rule *BlacklistRule) bool {
+	rule.Value = strings.TrimSpace(rule.Value)
+	if rule.Value == "" {
+		return false
+	}
+	if rule.Type == BlacklistRegex {
+		if _, err := regexp.Compile(rule.Value); err != nil {
+			l().Warnf("invalid blacklist regex %s: %s", rule.Value, err)
+			return false
+		}
+	}
+	b.lock.Lock()
+	for _, r := range b.Rules {
+		if r.Type == rule.Type && r.Value == rule.Value {
+			b.lock.Unlock()
+			return false
+		}
+	}
+	l().Infof("add blacklist rule %s", rule)
+	b.Rules = append(b.Rules, rule)
+	b.lock.Unlock()
+	b.Save()
+	return true
+}
+
+func (b *BlacklistStore) Remove(index int) {
+	b.lock.Lock()
+	if index < 0 || index >= len(b.Rules) {
+		b.lock.Unlock()
+		return
+	}
+	l().Infof("remove blacklist rule %s", b.Rules[index])
+	b.Rules = append(b.Rules[:index], b.Rules[index+1:]...)
+	b.lock.Unlock()
+	b.Save()
+}
+
+// AddBlacklistRule add rule to Blacklist and record who added it
+func AddBlacklistRule(rule *BlacklistRule, actor AuditActor) bool {
+	if !Blacklist.Add(rule) {
+		return false
+	}
+	Audit(actor, AuditBan, "%s", rule)
+	return true
+}
+
+// RemoveBlacklistRule remove rule at index from Blacklist and record who removed it
+func RemoveBlacklistRule(index int, actor AuditActor) {
+	rule := Blacklist.Get(index)
+	if rule == nil {
+		return
+	}
+	Blacklist.Remove(index)
+	Audit(actor, AuditUnban, "%s", rule)
+}
+
+func (b *BlacklistStore) getRegex(pattern string) *regexp.Regexp {
+	if r, ok := b.regex[pattern]; ok {
+		return r
+	}
+	r, err := regexp.Compile(pattern)
+	if err != nil {
+		r = nil
+	}
+	b.regex[pattern] = r
+	return r
+}
+
+// CheckUser return the matched rule if the user is banned, otherwise nil
+func (b *BlacklistStore) CheckUser(uid string) *BlacklistRule {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for _, r := range b.Rules {
+		if r.Type == BlacklistUser && r.Value == uid {
+			return r
+		}
+	}
+	return nil
+}
+
+// CheckMedia return the matched rule if the media is banned, otherwise nil
+func (b *BlacklistStore) CheckMedia(media *player.Media) *BlacklistRule {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	song := BlacklistSongValue(media)
+	title := strings.ToLower(media.Title)
+	for _, r := range b.Rules {
+		switch r.Type {
+		case BlacklistSong:
+			if song != "" && r.Value == song {
+				return r
+			}
+		case BlacklistArtist:
+			for _, artist := range strings.Split(media.Artist, ",") {
+				if strings.EqualFold(strings.TrimSpace(artist), r.Value) {
+					return r
+				}
+			}
+		case BlacklistKeyword:
+			if strings.Contains(title, strings.ToLower(r.Value)) {
+				return r
+			}
+		case BlacklistRegex:
+			if re := b.getRegex(r.Value); re != nil && re.MatchString(media.Title) {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// CheckRequest check both requester and media against blacklist,
+// return a BlacklistError if any rule matched.
+func (b *BlacklistStore) CheckRequest(media *player.Media, uid string) error {
+	if uid != "" {
+		if r := b.CheckUser(uid); r != nil {
+			return &BlacklistError{Rule: r}
+		}
+	}
+	if r := b.CheckMedia(media); r != nil {
+		return &BlacklistError{Rule: r}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlacklistStore_CheckRequest(t *testing.T) {
+	filename := filepath.Join(os.TempDir(), "blacklist_test.json")
+	defer os.Remove(filename)
+	_ = os.Remove(filename)
+	b := NewBlacklistStore(filename)
+	b.Add(&BlacklistRule{Type: BlacklistSong, Value: "netease:123"})
+	b.Add(&BlacklistRule{Type: BlacklistArtist, Value: "周杰伦"})
+	b.Add(&BlacklistRule{Type: BlacklistKeyword, Value: "DJ"})
+	b.Add(&BlacklistRule{Type: BlacklistRegex, Value: "(?i)live$"})
+	b.Add(&BlacklistRule{Type: BlacklistUser, Value: "10086"})
+	for _, c := range []struct {
+		media     *player.Media
+		uid       string
+		ruleType  BlacklistRuleType
+		ruleValue string
+	}{
+		{&player.Media{Title: "染", Artist: "reol", Meta: provider.Meta{Name: "netease", Id: "123"}}, "", BlacklistSong, "netease:123"},
+		{&player.Media{Title: "龙拳", Artist: "方文山, 周杰伦"}, "", BlacklistArtist, "周杰伦"},
+		{&player.Media{Title: "稻香 (dj版)"}, "", BlacklistKeyword, "DJ"},
+		{&player.Media{Title: "xxx Live"}, "", BlacklistRegex, "(?i)live$"},
+		{&player.Media{Title: "abc"}, "10086", BlacklistUser, "10086"},
+	} {
+		err, ok := b.CheckRequest(c.media, c.uid).(*BlacklistError)
+		if !ok || err.Rule.Type != c.ruleType || err.Rule.Value != c.ruleValue {
+			t.Fatalf("%s by %s should be rejected by %s rule %s", c.media.Title, c.uid, c.ruleType, c.ruleValue)
+		}
+	}
+	if r := b.CheckUser("10086"); r == nil || r.Type != BlacklistUser {
+		t.Fatal("user should be banned")
+	}
+	if b.CheckUser("1") != nil {
+		t.Fatal("user should not be banned")
+	}
+	if b.CheckRequest(&player.Media{Title: "abc", Artist: "def"}, "1") != nil {
+		t.Fatal("media should not be blacklisted")
+	}
+	if NewBlacklistStore(filename).Size() != 5 {
+		t.Fatal("blacklist should be persisted")
+	}
+}
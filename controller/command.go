@@ -1,31 +1,288 @@
 package controller
 
 import (
+	"AynaLivePlayer/config"
 	"AynaLivePlayer/event"
 	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/util"
+	"fmt"
 	"strings"
+	"sync"
+	"time"
 )
 
-var Commands []DanmuCommandExecutor
+// CommandArg describe an argument of a command
+type CommandArg struct {
+	Name     string
+	Required bool
+	// Rest means the argument takes all remaining text, it should be the last argument
+	Rest bool
+}
+
+// CommandPermission is names of roles allowed to run a command, see Role
+type CommandPermission []string
+
+var (
+	PermissionEveryone = CommandPermission{RoleUser}
+	PermissionAdmin    = CommandPermission{RoleAdmin}
+)
+
+func (p CommandPermission) Allow(user *liveclient.DanmuUser) bool {
+	return HasAnyRole(user, p)
+}
+
+type CommandContext struct {
+	Command *Command
+	// Name is the name or alias used by user
+	Name    string
+	Args    map[string]string
+	RawArgs []string
+	Danmu   *liveclient.DanmuMessage
+}
+
+func (c *CommandContext) Arg(name string) string {
+	return c.Args[name]
+}
+
+func (c *CommandContext) User() *liveclient.DanmuUser {
+	return &c.Danmu.User
+}
+
+// Actor return the user who run the command as audit actor
+func (c *CommandContext) Actor() AuditActor {
+	return DanmuActor(c.User())
+}
+
+// Reply send a message to the user who run the command
+func (c *CommandContext) Reply(msg string) {
+	Reply(c.User(), msg)
+}
+
+// ReplyTemplate format the template and reply, see ReplyTemplate.
+func (c *CommandContext) ReplyTemplate(template string, values map[string]interface{}) {
+	ReplyTemplate(c.User(), template, values)
+}
+
+// Command is a danmu command spec. Aliases, Permission and cooldowns can be changed at runtime.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Args        []CommandArg
+	Permission  CommandPermission
+	// UserCooldown and GlobalCooldown are in seconds, <= 0 means no cooldown.
+	// users with admin role are not limited by cooldowns.
+	UserCooldown   int
+	GlobalCooldown int
+	// Cooldowns persist user cooldowns, they are kept in memory if nil
+	Cooldowns *CooldownStore
+	// Execute run the command, cooldown is only counted when it returns nil
+	Execute func(ctx *CommandContext) error
+
+	lastUsed   map[string]int64
+	lastGlobal int64
+	lock       sync.Mutex
+}
+
+func (c *Command) Match(name string) bool {
+	if name == "" {
+		return false
+	}
+	if c.Name == name {
+		return true
+	}
+	for _, a := range c.Aliases {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage return command with arguments, like 点歌 <keyword> [provider]
+func (c *Command) Usage() string {
+	var sb strings.Builder
+	sb.WriteString(c.Name)
+	for _, arg := range c.Args {
+		if arg.Required {
+			sb.WriteString(fmt.Sprintf(" <%s>", arg.Name))
+		} else {
+			sb.WriteString(fmt.Sprintf(" [%s]", arg.Name))
+		}
+	}
+	return sb.String()
+}
+
+// cooldown return remaining cooldown seconds for the user
+func (c *Command) cooldown(user *liveclient.DanmuUser, now int64) int64 {
+	if HasRole(user, RoleAdmin) {
+		return 0
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var remain int64
+	if c.GlobalCooldown > 0 {
+		remain = c.lastGlobal + int64(c.GlobalCooldown) - now
+	}
+	if c.UserCooldown > 0 {
+		if r := c.userLastUsed(user.Uid) + int64(c.UserCooldown) - now; r > remain {
+			remain = r
+		}
+	}
+	return remain
+}
+
+// userLastUsed return the last time user ran the command, lock should be held by caller
+func (c *Command) userLastUsed(uid string) int64 {
+	if c.Cooldowns != nil {
+		return c.Cooldowns.Get(uid)
+	}
+	return c.lastUsed[uid]
+}
+
+func (c *Command) recordUse(uid string, now int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lastGlobal = now
+	if c.Cooldowns != nil {
+		c.Cooldowns.Record(uid, now, c.UserCooldown)
+		return
+	}
+	if c.lastUsed == nil {
+		c.lastUsed = make(map[string]int64)
+	}
+	c.lastUsed[uid] = now
+}
 
-type DanmuCommandExecutor interface {
-	Match(command string) bool
-	Execute(command string, args []string, danmu *liveclient.DanmuMessage)
+// parseArgs map raw arguments to argument names, return error if required argument is missing
+func (c *Command) parseArgs(raw []string) (map[string]string, error) {
+	args := make(map[string]string)
+	for i, arg := range c.Args {
+		if i >= len(raw) {
+			if arg.Required {
+				return args, ErrorCommandArgs
+			}
+			continue
+		}
+		if arg.Rest {
+			args[arg.Name] = strings.Join(raw[i:], " ")
+			break
+		}
+		args[arg.Name] = raw[i]
+	}
+	return args, nil
 }
 
-func AddCommand(executors ...DanmuCommandExecutor) {
-	Commands = append(Commands, executors...)
+var Commands []*Command
+var commandLock sync.RWMutex
+
+func RegisterCommand(commands ...*Command) {
+	commandLock.Lock()
+	defer commandLock.Unlock()
+	Commands = append(Commands, commands...)
+}
+
+func FindCommand(name string) *Command {
+	commandLock.RLock()
+	defer commandLock.RUnlock()
+	for _, c := range Commands {
+		if c.Match(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// HelpCommand list all commands available for the user, or show usage of the given command
+var HelpCommand = &Command{
+	Name:         "帮助",
+	Aliases:      []string{"help"},
+	Description:  "查看可用指令, 或者查看指令的用法",
+	Args:         []CommandArg{{Name: "command"}},
+	Permission:   PermissionEveryone,
+	UserCooldown: 30,
+	Execute: func(ctx *CommandContext) error {
+		if name := ctx.Arg("command"); name != "" {
+			if c := FindCommand(name); c != nil {
+				ctx.Reply(strings.TrimSpace(c.Usage() + " " + c.Description))
+			}
+			return nil
+		}
+		commandLock.RLock()
+		names := make([]string, 0, len(Commands))
+		for _, c := range Commands {
+			if c != ctx.Command && c.Permission.Allow(ctx.User()) {
+				names = append(names, c.Name)
+			}
+		}
+		commandLock.RUnlock()
+		ctx.Reply(strings.Join(names, " "))
+		return nil
+	},
+}
+
+func init() {
+	RegisterCommand(HelpCommand)
 }
 
 func danmuCommandHandler(event *event.Event) {
 	danmu := event.Data.(*liveclient.DanmuMessage)
-	args := strings.Split(danmu.Message, " ")
-	if len(args[0]) == 0 {
+	entry := &DanmuLogEntry{Time: time.Now().Unix(), User: danmu.User, Message: danmu.Message}
+	defer DanmuLog.Add(entry)
+	args := util.SplitArgs(danmu.Message)
+	if len(args) == 0 {
+		return
+	}
+	cmd := FindCommand(args[0])
+	if cmd == nil {
 		return
 	}
-	for _, cmd := range Commands {
-		if cmd.Match(args[0]) {
-			cmd.Execute(args[0], args[1:], danmu)
+	entry.Command = cmd.Name
+	entry.Result = DanmuLogSuccess
+	if err := ExecuteCommand(cmd, args[0], args[1:], danmu); err != nil {
+		entry.Result = err.Error()
+	}
+}
+
+// ExecuteCommand check permission, cooldown and arguments then run the command,
+// return the reason if command is not run or the error returned by command.
+func ExecuteCommand(cmd *Command, name string, rawArgs []string, danmu *liveclient.DanmuMessage) error {
+	user := &danmu.User
+	l().Infof("%s(%s) execute command: %s %s", user.Username, user.Uid, name, rawArgs)
+	if !cmd.Permission.Allow(user) {
+		l().Infof("%s(%s) has no permission to run %s", user.Username, user.Uid, cmd.Name)
+		return ErrorCommandPermission
+	}
+	now := time.Now().Unix()
+	if remain := cmd.cooldown(user, now); remain > 0 {
+		l().Infof("command %s of %s(%s) still in cool down for %ds", cmd.Name, user.Username, user.Uid, remain)
+		return ErrorCommandCooldown
+	}
+	ctx := &CommandContext{Command: cmd, Name: name, RawArgs: rawArgs, Danmu: danmu}
+	args, err := cmd.parseArgs(rawArgs)
+	if err != nil {
+		ctx.Reply(cmd.Usage())
+		return err
+	}
+	ctx.Args = args
+	// points are paid before execution and refunded if command failed
+	price := 0
+	if config.Points.Enable && !HasRole(user, RoleAdmin) {
+		price = CommandPrice(cmd.Name)
+	}
+	if price > 0 {
+		if err = Points.Spend(user.Uid, user.Username, price, "command "+cmd.Name); err != nil {
+			ctx.ReplyTemplate(config.Points.ReplyNotEnough, map[string]interface{}{"price": price})
+			return err
+		}
+	}
+	if err = cmd.Execute(ctx); err != nil {
+		l().Infof("command %s of %s(%s) failed: %s", cmd.Name, user.Username, user.Uid, err)
+		if price > 0 {
+			Points.Add(user.Uid, user.Username, price, "refund "+cmd.Name)
 		}
+		return err
 	}
+	cmd.recordUse(user.Uid, now)
+	return nil
 }
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"AynaLivePlayer/liveclient"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommand_Execute(t *testing.T) {
+	var keyword string
+	SetRoles([]*Role{{Name: "medal5", MedalLevel: 5}})
+	cmd := &Command{
+		Name:         "test",
+		Aliases:      []string{"t"},
+		Args:         []CommandArg{{Name: "keyword", Required: true, Rest: true}},
+		Permission:   CommandPermission{"medal5", RoleAdmin},
+		UserCooldown: 60,
+		Execute: func(ctx *CommandContext) error {
+			keyword = ctx.Arg("keyword")
+			return nil
+		},
+	}
+	fmt.Println(cmd.Usage())
+	user := liveclient.DanmuUser{Uid: "1", Username: "user"}
+	ExecuteCommand(cmd, "t", []string{"a", "b"}, &liveclient.DanmuMessage{User: user})
+	if keyword != "" {
+		t.Fatal("medal level is not enough")
+	}
+	user.Medal.Level = 5
+	ExecuteCommand(cmd, "t", []string{"a", "b"}, &liveclient.DanmuMessage{User: user})
+	if keyword != "a b" {
+		t.Fatal("rest argument should take all remaining text")
+	}
+	ExecuteCommand(cmd, "t", []string{"c"}, &liveclient.DanmuMessage{User: user})
+	if keyword != "a b" {
+		t.Fatal("user should be in cool down")
+	}
+	user.Admin = true
+	ExecuteCommand(cmd, "t", []string{"c"}, &liveclient.DanmuMessage{User: user})
+	if keyword != "c" {
+		t.Fatal("admin should not be limited by cool down")
+	}
+}
+
+func TestFormatReply(t *testing.T) {
+	msg := FormatReply("已点歌: {title} - 第{pos}位 {unknown}", map[string]interface{}{"title": "晴天", "pos": 3})
+	fmt.Println(msg)
+	if msg != "已点歌: 晴天 - 第3位 {unknown}" {
+		t.Fatal("template is not formatted correctly")
+	}
+}
+
+func TestCooldownStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cooldown.json")
+	cmd := &Command{
+		Name:         "test",
+		Permission:   PermissionEveryone,
+		UserCooldown: 60,
+		Cooldowns:    NewCooldownStore(filename),
+		Execute: func(ctx *CommandContext) error {
+			return nil
+		},
+	}
+	danmu := &liveclient.DanmuMessage{User: liveclient.DanmuUser{Uid: "1", Username: "user"}}
+	if err := ExecuteCommand(cmd, "test", nil, danmu); err != nil {
+		t.Fatal(err)
+	}
+	// cooldown should be restored from file after restart
+	cmd.Cooldowns = NewCooldownStore(filename)
+	fmt.Println(cmd.Cooldowns.LastUsed)
+	if ExecuteCommand(cmd, "test", nil, danmu) != ErrorCommandCooldown {
+		t.Fatal("cooldown should be persisted")
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// changes of config are recorded in audit log after editing stops for a while
+const configAuditDelay = 2 * time.Second
+
+// ConfigAuditor record changed fields of a config in audit log. Exported fields are compared,
+// fields tagged `audit:"-"` are ignored and fields tagged `audit:"secret"` are recorded without value.
+type ConfigAuditor struct {
+	cfg      config.Config
+	actor    AuditActor
+	snapshot map[string]string
+	timer    *time.Timer
+	lock     sync.Mutex
+}
+
+func NewConfigAuditor(actor AuditActor, cfg config.Config) *ConfigAuditor {
+	return &ConfigAuditor{
+		cfg:      cfg,
+		actor:    actor,
+		snapshot: configValues(cfg),
+	}
+}
+
+// Changed record the changes after editing stopped, it can be called on every keystroke
+func (a *ConfigAuditor) Changed() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.timer = time.AfterFunc(configAuditDelay, a.Flush)
+}
+
+// Flush record the changes since last record immediately, nothing is recorded if config is not changed
+func (a *ConfigAuditor) Flush() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if changes := a.diff(); len(changes) > 0 {
+		Audit(a.actor, AuditConfig, "%s %s", a.cfg.Name(), strings.Join(changes, ", "))
+	}
+}
+
+// Sync take current config as recorded without adding audit record,
+// it should be called after config is changed by others which are audited separately.
+func (a *ConfigAuditor) Sync() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.snapshot = configValues(a.cfg)
+}
+
+// diff update snapshot and return changes in field order, lock must be held
+func (a *ConfigAuditor) diff() []string {
+	current := configValues(a.cfg)
+	changes := make([]string, 0)
+	t := reflect.Indirect(reflect.ValueOf(a.cfg)).Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value, ok := current[field.Name]
+		if !ok || value == a.snapshot[field.Name] {
+			continue
+		}
+		if field.Tag.Get("audit") == "secret" {
+			changes = append(changes, field.Name+" changed")
+		} else {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", field.Name, a.snapshot[field.Name], value))
+		}
+	}
+	a.snapshot = current
+	return changes
+}
+
+// configValues return formatted values of exported fields of cfg
+func configValues(cfg config.Config) map[string]string {
+	values := make(map[string]string)
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return values
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" || field.Tag.Get("audit") == "-" || field.Tag.Get("ini") == "-" {
+			continue
+		}
+		values[field.Name] = fmt.Sprint(v.Field(i).Interface())
+	}
+	return values
+}
@@ -11,6 +11,8 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const MODULE_CONTROLLER = "Controller"
@@ -19,10 +21,42 @@ func l() *logrus.Entry {
 	return logger.Logger.WithField("Module", MODULE_CONTROLLER)
 }
 
+// TwitchRoomPrefix is the prefix of twitch channel in room id, like twitch:channel
+const TwitchRoomPrefix = "twitch:"
+
+// newLiveClient create live client by room id, numeric room id is bilibili room
+// and room id starting with TwitchRoomPrefix is twitch channel.
+func newLiveClient(roomId string) (liveclient.LiveClient, error) {
+	interval := time.Duration(config.LiveRoom.MessageInterval) * time.Millisecond
+	if strings.HasPrefix(roomId, TwitchRoomPrefix) {
+		channel := strings.TrimSpace(strings.TrimPrefix(roomId, TwitchRoomPrefix))
+		if channel == "" {
+			return nil, ErrorInvalidRoomId
+		}
+		return liveclient.NewTwitchWithAccount(channel,
+			liveclient.TwitchAccount{
+				Username: config.LiveRoom.TwitchUsername,
+				Token:    config.LiveRoom.TwitchToken,
+			},
+			interval, liveclient.TwitchMessageMaxLength), nil
+	}
+	room, err := strconv.Atoi(roomId)
+	if err != nil {
+		return nil, err
+	}
+	return liveclient.NewBilibiliWithAccount(room,
+		liveclient.BilibiliAccount{
+			Uid:      config.LiveRoom.BilibiliUid,
+			SessData: config.LiveRoom.BilibiliSessData,
+			BiliJct:  config.LiveRoom.BilibiliJct,
+		},
+		interval, config.LiveRoom.MessageMaxLength), nil
+}
+
 func SetDanmuClient(roomId string) {
 	ResetDanmuClient()
 	l().Infof("setting live client for %s", roomId)
-	room, err := strconv.Atoi(roomId)
+	client, err := newLiveClient(roomId)
 	if err != nil {
 		l().Warn("parse room id error", err)
 		return
@@ -30,7 +64,7 @@ func SetDanmuClient(roomId string) {
 	if !util.StringSliceContains(config.LiveRoom.History, roomId) {
 		config.LiveRoom.History = append(config.LiveRoom.History, roomId)
 	}
-	LiveClient = liveclient.NewBilibili(room)
+	LiveClient = client
 	LiveClient.Handler().Register(&event.EventHandler{
 		EventId: liveclient.EventMessageReceive,
 		Name:    "controller.commandexecutor",
@@ -40,9 +74,31 @@ func SetDanmuClient(roomId string) {
 		liveclient.EventMessageReceive,
 		"controller.danmu.handler",
 		danmuHandler)
+	LiveClient.Handler().RegisterA(
+		liveclient.EventLiveStatus,
+		"controller.setlist",
+		handleSetlistLiveStatus)
+	for _, id := range forwardedLiveEvents {
+		// handlers are identified by name, so every event needs its own name
+		LiveClient.Handler().RegisterA(id, "controller.forward."+string(id), forwardLiveEvent)
+	}
 	l().Infof("setting live client for %s success", roomId)
 }
 
+// forwardedLiveEvents are passed to EventHandler, so plugins can handle them
+// without registering again when live client changes.
+var forwardedLiveEvents = []event.EventId{
+	liveclient.EventGiftReceive,
+	liveclient.EventSuperChat,
+	liveclient.EventGuardBuy,
+	liveclient.EventUserEnter,
+	liveclient.EventUserFollow,
+}
+
+func forwardLiveEvent(event *event.Event) {
+	EventHandler.CallA(event.Id, event.Data)
+}
+
 func StartDanmuClient() {
 	LiveClient.Connect()
 }
@@ -68,9 +124,12 @@ func AddPlaylist(pname string, uri string) *player.Playlist {
 		Name: pname,
 		Id:   id,
 	}
+	// weights of old config are built before the new playlist is added
+	normalizePlaylistWeights()
 	PlaylistManager = append(PlaylistManager, p)
 	config.Player.Playlists = append(config.Player.Playlists, id)
 	config.Player.PlaylistsProvider = append(config.Player.PlaylistsProvider, pname)
+	config.Player.PlaylistsWeight = append(config.Player.PlaylistsWeight, 0)
 	return p
 }
 
@@ -80,9 +139,11 @@ func RemovePlaylist(index int) {
 		l().Warnf("playlist.index=%d not found", index)
 		return
 	}
+	normalizePlaylistWeights()
+	member := GetSystemPlaylistWeight(index) > 0
 	if index == config.Player.PlaylistIndex {
-		l().Info("Delete current system playlist, reset system playlist to index = 0")
-		SetSystemPlaylist(0)
+		l().Info("Delete current system playlist, reset system playlist index to 0")
+		config.Player.PlaylistIndex = 0
 	}
 	if index < config.Player.PlaylistIndex {
 		l().Debugf("Delete playlist before system playlist (index=%d), reduce system playlist index by 1", config.Player.PlaylistIndex)
@@ -91,6 +152,18 @@ func RemovePlaylist(index int) {
 	PlaylistManager = append(PlaylistManager[:index], PlaylistManager[index+1:]...)
 	config.Player.Playlists = append(config.Player.Playlists[:index], config.Player.Playlists[index+1:]...)
 	config.Player.PlaylistsProvider = append(config.Player.PlaylistsProvider[:index], config.Player.PlaylistsProvider[index+1:]...)
+	config.Player.PlaylistsWeight = append(config.Player.PlaylistsWeight[:index], config.Player.PlaylistsWeight[index+1:]...)
+	if !member {
+		return
+	}
+	for i := range PlaylistManager {
+		if GetSystemPlaylistWeight(i) > 0 {
+			RefreshSystemPlaylist()
+			return
+		}
+	}
+	l().Info("no system playlist left, reset system playlist to index = 0")
+	SetSystemPlaylist(0)
 }
 
 func SetSystemPlaylist(index int) {
@@ -103,10 +176,17 @@ func SetSystemPlaylist(index int) {
 	if err != nil {
 		return
 	}
-	medias := PlaylistManager[index].Playlist
 	config.Player.PlaylistIndex = index
-	ApplyUser(medias, player.PlaylistUser)
-	SystemPlaylist.Replace(medias)
+	normalizePlaylistWeights()
+	// set as system playlist means it is the only playlist in idle rotation
+	for i := range config.Player.PlaylistsWeight {
+		if i != index {
+			config.Player.PlaylistsWeight[i] = 0
+		} else if config.Player.PlaylistsWeight[i] <= 0 {
+			config.Player.PlaylistsWeight[i] = 1
+		}
+	}
+	RefreshSystemPlaylist()
 }
 
 func PreparePlaylistByIndex(index int) {
@@ -119,4 +199,7 @@ func PreparePlaylistByIndex(index int) {
 	if err != nil {
 		return
 	}
+	if GetSystemPlaylistWeight(index) > 0 {
+		RefreshSystemPlaylist()
+	}
 }
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"AynaLivePlayer/util"
+	"sync"
+)
+
+// CooldownStore keep the last time each user ran a command, it is saved whenever
+// a use is recorded so restarting the app doesn't reset cooldowns.
+type CooldownStore struct {
+	LastUsed map[string]int64
+	filename string
+	lock     sync.Mutex
+}
+
+func NewCooldownStore(filename string) *CooldownStore {
+	s := &CooldownStore{
+		LastUsed: make(map[string]int64),
+		filename: filename,
+	}
+	if err := util.LoadJson(filename, s); err != nil {
+		l().Infof("load cooldown store from %s failed: %s", filename, err)
+	}
+	if s.LastUsed == nil {
+		s.LastUsed = make(map[string]int64)
+	}
+	return s
+}
+
+// Get return the last time the user ran the command, 0 if never
+func (s *CooldownStore) Get(uid string) int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.LastUsed[uid]
+}
+
+// Record save the time user ran the command, records out of cooldown seconds are removed
+func (s *CooldownStore) Record(uid string, now int64, cooldown int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for u, t := range s.LastUsed {
+		if now-t >= int64(cooldown) {
+			delete(s.LastUsed, u)
+		}
+	}
+	s.LastUsed[uid] = now
+	if err := util.SaveJson(s.filename, s); err != nil {
+		l().Warnf("save cooldown store to %s failed: %s", s.filename, err)
+	}
+}
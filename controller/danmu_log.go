@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/liveclient"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const EventDanmuLogUpdate event.EventId = "controller.danmulog.update"
+
+type DanmuLogUpdateEvent struct {
+	Entry *DanmuLogEntry
+}
+
+// number of danmu kept in memory
+const DanmuLogSize = 2000
+
+// DanmuLogSuccess is the result of command executed successfully
+const DanmuLogSuccess = "ok"
+
+type DanmuLogEntry struct {
+	Time    int64
+	User    liveclient.DanmuUser
+	Message string
+	// Command is the name of command triggered by the danmu, empty if it is not a command
+	Command string
+	// Result is DanmuLogSuccess or the reason of failure
+	Result string
+}
+
+func (e *DanmuLogEntry) String() string {
+	var sb strings.Builder
+	sb.WriteString(time.Unix(e.Time, 0).Format("2006-01-02 15:04:05 "))
+	if e.User.Medal.Name != "" {
+		sb.WriteString(fmt.Sprintf("[%s %d] ", e.User.Medal.Name, e.User.Medal.Level))
+	}
+	sb.WriteString(fmt.Sprintf("%s(%s): %s", e.User.Username, e.User.Uid, e.Message))
+	if e.Command != "" {
+		sb.WriteString(fmt.Sprintf(" => %s: %s", e.Command, e.Result))
+	}
+	return sb.String()
+}
+
+// Match return true if keyword is in username, uid, medal, message or command, case-insensitive
+func (e *DanmuLogEntry) Match(keyword string) bool {
+	keyword = strings.ToLower(keyword)
+	for _, s := range []string{e.User.Username, e.User.Uid, e.User.Medal.Name, e.Message, e.Command} {
+		if strings.Contains(strings.ToLower(s), keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// DanmuLogStore keep recent danmu in memory, oldest entries are dropped when it is full
+type DanmuLogStore struct {
+	Entries []*DanmuLogEntry
+	Handler *event.Handler
+	size    int
+	lock    sync.RWMutex
+}
+
+var DanmuLog = NewDanmuLogStore(DanmuLogSize)
+
+func NewDanmuLogStore(size int) *DanmuLogStore {
+	return &DanmuLogStore{
+		Entries: make([]*DanmuLogEntry, 0),
+		Handler: event.NewHandler(),
+		size:    size,
+	}
+}
+
+func (s *DanmuLogStore) Add(entry *DanmuLogEntry) {
+	s.lock.Lock()
+	s.Entries = append(s.Entries, entry)
+	if len(s.Entries) > s.size {
+		s.Entries = s.Entries[len(s.Entries)-s.size:]
+	}
+	s.lock.Unlock()
+	s.Handler.CallA(EventDanmuLogUpdate, DanmuLogUpdateEvent{Entry: entry})
+}
+
+// Query return entries matching keyword, empty keyword means all
+func (s *DanmuLogStore) Query(keyword string) []*DanmuLogEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	keyword = strings.TrimSpace(keyword)
+	entries := make([]*DanmuLogEntry, 0, len(s.Entries))
+	for _, e := range s.Entries {
+		if keyword == "" || e.Match(keyword) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func ExportDanmuLog(w io.Writer, entries []*DanmuLogEntry) error {
+	for _, e := range entries {
+		if _, err := io.WriteString(w, e.String()+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
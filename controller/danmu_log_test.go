@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"AynaLivePlayer/liveclient"
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestDanmuLogStore_Add(t *testing.T) {
+	s := NewDanmuLogStore(3)
+	for i := 0; i < 5; i++ {
+		s.Add(&DanmuLogEntry{
+			User:    liveclient.DanmuUser{Uid: fmt.Sprintf("%d", i), Username: fmt.Sprintf("user%d", i)},
+			Message: fmt.Sprintf("message %d", i),
+		})
+	}
+	if len(s.Entries) != 3 || s.Entries[0].User.Uid != "2" {
+		t.Fatal("oldest entries should be dropped")
+	}
+	s.Entries[2].Command, s.Entries[2].Result = "点歌", DanmuLogSuccess
+	if len(s.Query("USER3")) != 1 || len(s.Query("点歌")) != 1 || len(s.Query("")) != 3 {
+		t.Fatal("query does not match")
+	}
+	var buf bytes.Buffer
+	if err := ExportDanmuLog(&buf, s.Query("")); err != nil {
+		t.Fatal(err)
+	}
+	fmt.Print(buf.String())
+}
@@ -0,0 +1,11 @@
+package controller
+
+import "errors"
+
+var (
+	ErrorNoResult          = errors.New("no search result")
+	ErrorCommandArgs       = errors.New("missing command arguments")
+	ErrorCommandPermission = errors.New("no permission to run command")
+	ErrorCommandCooldown   = errors.New("command is in cool down")
+	ErrorInvalidRoomId     = errors.New("invalid room id")
+)
@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/player"
+)
+
+// EventHandler is used by controller and plugins to publish events which are not bind to a player or playlist
+var EventHandler = event.NewHandler()
+
+const (
+	EventSkipVoteUpdate event.EventId = "controller.skipvote.update"
+)
+
+type SkipVoteUpdateEvent struct {
+	Media    *player.Media
+	Votes    int
+	Required int
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
+	"AynaLivePlayer/util"
+	"math"
+)
+
+// fallbackCandidateLimit is the maximum number of search results checked for each provider
+const fallbackCandidateLimit = 8
+
+// fallbackScore return how confident the candidate is the same media as the origin one
+func fallbackScore(origin *player.Media, candidate *player.Media) float64 {
+	title := util.StringSimilarity(origin.Title, candidate.Title)
+	artist := util.StringSimilarity(origin.Artist, candidate.Artist)
+	if origin.Duration <= 0 || candidate.Duration <= 0 {
+		return title*0.65 + artist*0.35
+	}
+	diff := math.Abs(float64(origin.Duration - candidate.Duration))
+	duration := math.Max(0, 1-diff/math.Max(float64(origin.Duration), 1))
+	return title*0.55 + artist*0.3 + duration*0.15
+}
+
+// FindFallbackMedia search other providers for the same media when the origin one can't be played.
+// return nil if no confident match is found
+func FindFallbackMedia(media *player.Media) *player.Media {
+	if !config.Provider.Fallback || media.Title == "" {
+		return nil
+	}
+	origin, ok := media.Meta.(provider.Meta)
+	if !ok {
+		return nil
+	}
+	keyword := media.Title + " " + media.Artist
+	l().Infof("try find fallback media for %s (%s) from %s", media.Title, media.Artist, origin.Name)
+	for _, pname := range config.Provider.Priority {
+		if pname == origin.Name {
+			continue
+		}
+		pr, ok := provider.Providers[pname]
+		if !ok {
+			l().Warnf("Provider %s not exist", pname)
+			continue
+		}
+		medias, err := pr.Search(keyword)
+		if err != nil {
+			l().Warnf("fallback search using %s failed: %s", pname, err)
+			continue
+		}
+		var best *player.Media
+		bestScore := 0.0
+		for i := 0; i < len(medias) && i < fallbackCandidateLimit; i++ {
+			score := fallbackScore(media, medias[i])
+			l().Tracef("fallback candidate %s (%s) from %s score=%f", medias[i].Title, medias[i].Artist, pname, score)
+			if score > bestScore {
+				best, bestScore = medias[i], score
+			}
+		}
+		if best == nil || bestScore < config.Provider.FallbackThreshold {
+			continue
+		}
+		if err = PrepareMedia(best); err != nil {
+			l().Warnf("fallback media %s from %s can't be prepared", best.Title, pname)
+			continue
+		}
+		meta := best.Meta.(provider.Meta)
+		meta.Origin = &origin
+		best.Meta = meta
+		best.User = media.User
+		l().Infof("use fallback media %s (%s) from %s, score=%f", best.Title, best.Artist, pname, bestScore)
+		return best
+	}
+	l().Infof("no fallback media found for %s", media.Title)
+	return nil
+}
@@ -10,8 +10,6 @@ import (
 
 var MainPlayer *player.Player
 var UserPlaylist *player.Playlist
-var History *player.Playlist
-var HistoryUser *player.User
 var SystemPlaylist *player.Playlist
 var LiveClient liveclient.LiveClient
 var PlaylistManager []*player.Playlist
@@ -25,16 +23,30 @@ func Initialize() {
 	SetVolume(config.Player.Volume)
 	UserPlaylist = player.NewPlaylist("user", player.PlaylistConfig{RandomNext: false})
 	SystemPlaylist = player.NewPlaylist("system", player.PlaylistConfig{RandomNext: config.Player.PlaylistRandom})
+	PendingPlaylist = player.NewPlaylist("pending", player.PlaylistConfig{RandomNext: false})
 	PlaylistManager = make([]*player.Playlist, 0)
 	CurrentLyric = player.NewLyric("")
 	loadPlaylists()
 
-	History = player.NewPlaylist("history", player.PlaylistConfig{RandomNext: false})
 	HistoryUser = &player.User{Name: "History"}
+	Blacklist = NewBlacklistStore(BlacklistPath)
+	Stats = NewStatsStore(StatsPath)
+	CurrentSetlist = loadSetlist()
+	Points = NewPointsStore(PointsPath)
+	AuditLog = NewAuditStore(AuditPath)
+	ReloadRoles()
 
 	MainPlayer.ObserveProperty("idle-active", handleMpvIdlePlayNext)
 	UserPlaylist.Handler.RegisterA(player.EventPlaylistInsert, "controller.playnextwhenadd", handlePlaylistAdd)
-	MainPlayer.ObserveProperty("time-pos", handleLyricUpdate)
+	UserPlaylist.Handler.RegisterA(player.EventPlaylistInsert, "controller.autoplay", handleAutoplayRequest)
+	UserPlaylist.Handler.RegisterA(player.EventPlaylistUpdate, "controller.prefetch", handlePrefetchQueueUpdate)
+	SystemPlaylist.Handler.RegisterA(player.EventPlaylistUpdate, "controller.prefetch", handlePrefetchSystemUpdate)
+	MainPlayer.ObserveProperty("time-pos", handleLyricUpdate, handleStatsPosition)
+	MainPlayer.EventHandler.RegisterA(player.EventPlay, "controller.stats", handleStatsPlay)
+	MainPlayer.EventHandler.RegisterA(player.EventPlay, "controller.setlist", handleSetlistPlay)
+	EventHandler.RegisterA(liveclient.EventGiftReceive, "controller.points.gift", handlePointsGift)
+	EventHandler.RegisterA(liveclient.EventGuardBuy, "controller.points.guard", handlePointsGuard)
+	AddDanmuHandler(pointsDanmuHandler{})
 	MainPlayer.Start()
 
 }
@@ -55,16 +67,18 @@ func loadPlaylists() {
 		}
 		PlaylistManager = append(PlaylistManager, p)
 	}
-	if config.Player.PlaylistIndex < 0 || config.Player.PlaylistIndex >= len(config.Player.Playlists) {
-		l().Warn("playlist index did not find")
-		return
-	}
-	go func() {
-		c := config.Player.PlaylistIndex
-		err := PreparePlaylist(PlaylistManager[c])
-		if err != nil {
-			return
+	normalizePlaylistWeights()
+	// each playlist in idle rotation is prepared independently
+	for i := range PlaylistManager {
+		if GetSystemPlaylistWeight(i) <= 0 {
+			continue
 		}
-		SetSystemPlaylist(c)
-	}()
+		go func(index int) {
+			err := PreparePlaylist(PlaylistManager[index])
+			if err != nil {
+				return
+			}
+			RefreshSystemPlaylist()
+		}(i)
+	}
 }
@@ -11,6 +11,7 @@ func handleMpvIdlePlayNext(property *mpv.EventProperty) {
 	isIdle := property.Data.(mpv.Node).Value.(bool)
 	if isIdle {
 		l().Info("mpv went idle, try play next")
+		Stats.Finish(false)
 		PlayNext()
 	}
 }
@@ -20,7 +21,8 @@ func handlePlaylistAdd(event *event.Event) {
 		PlayNext()
 		return
 	}
-	if config.Player.SkipPlaylist && CurrentMedia != nil && CurrentMedia.User == player.PlaylistUser {
+	if config.Player.SkipPlaylist && CurrentMedia != nil &&
+		(CurrentMedia.User == player.PlaylistUser || CurrentMedia.User == player.AutoplayUser) {
 		PlayNext()
 		return
 	}
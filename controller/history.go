@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
+	"AynaLivePlayer/util"
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+)
+
+const EventHistoryUpdate event.EventId = "controller.history.update"
+
+type HistoryUpdateEvent struct {
+	Record *PlayRecord
+}
+
+// ToMedia create a media which can be played again, requester is replaced by HistoryUser
+func (r *PlayRecord) ToMedia() *player.Media {
+	return &player.Media{
+		Title:  r.Title,
+		Artist: r.Artist,
+		Album:  r.Album,
+		Cover:  player.Picture{Url: r.Cover},
+		User:   HistoryUser,
+		Meta: provider.Meta{
+			Name: r.Provider,
+			Id:   r.Id,
+		},
+	}
+}
+
+// HistoryFilter filter play records in history, empty field matches everything.
+// text fields are matched case-insensitively by substring, Since and Until are unix timestamps.
+type HistoryFilter struct {
+	Title     string
+	Artist    string
+	Requester string
+	Provider  string
+	Since     int64
+	Until     int64
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func (f *HistoryFilter) Match(r *PlayRecord) bool {
+	if f.Since > 0 && r.Time < f.Since {
+		return false
+	}
+	if f.Until > 0 && r.Time >= f.Until {
+		return false
+	}
+	if f.Provider != "" && r.Provider != f.Provider {
+		return false
+	}
+	if f.Requester != "" && !containsFold(r.Requester, f.Requester) && r.RequesterUid != f.Requester {
+		return false
+	}
+	return containsFold(r.Title, f.Title) && containsFold(r.Artist, f.Artist)
+}
+
+var HistoryUser *player.User
+
+// history return play records latest first, the playing media is included
+func (s *StatsStore) history() []*PlayRecord {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	records := make([]*PlayRecord, 0, len(s.Records)+1)
+	if s.current != nil {
+		records = append(records, s.current)
+	}
+	for i := len(s.Records) - 1; i >= 0; i-- {
+		records = append(records, s.Records[i])
+	}
+	return records
+}
+
+// Query return play records matched by filter, latest first
+func (s *StatsStore) Query(filter HistoryFilter) []*PlayRecord {
+	result := make([]*PlayRecord, 0)
+	for _, r := range s.history() {
+		if filter.Match(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// Providers return all provider names appeared in play records
+func (s *StatsStore) Providers() []string {
+	names := make([]string, 0)
+	for _, r := range s.history() {
+		if r.Provider != "" && !util.StringSliceContains(names, r.Provider) {
+			names = append(names, r.Provider)
+		}
+	}
+	return names
+}
+
+func ExportHistoryJSON(w io.Writer, records []*PlayRecord) error {
+	content, err := util.MarshalIndentUnescape(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+func ExportHistoryCSV(w io.Writer, records []*PlayRecord) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"Time", "Title", "Artist", "Album", "Provider", "Id", "Requester", "RequesterUid"})
+	for _, r := range records {
+		_ = writer.Write([]string{
+			time.Unix(r.Time, 0).Format("2006-01-02 15:04:05"),
+			r.Title, r.Artist, r.Album, r.Provider, r.Id, r.Requester, r.RequesterUid,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -2,34 +2,51 @@ package controller
 
 import (
 	"AynaLivePlayer/config"
+	"AynaLivePlayer/liveclient"
 	"AynaLivePlayer/player"
 	"AynaLivePlayer/provider"
+	"strings"
 )
 
 func PlayNext() {
 	l().Info("try to play next possible media")
-	if UserPlaylist.Size() == 0 && SystemPlaylist.Size() == 0 {
-		return
-	}
 	var media *player.Media
 	if UserPlaylist.Size() != 0 {
 		media = UserPlaylist.Pop()
 	} else if SystemPlaylist.Size() != 0 {
-		media = SystemPlaylist.Next()
+		media = nextSystemMedia()
+	}
+	if media == nil && config.Player.Autoplay {
+		media = nextAutoplayMedia()
+	}
+	if media == nil {
+		return
 	}
 	Play(media)
 }
 
+// Skip play next media and record who skipped current media
+func Skip(actor AuditActor) {
+	if CurrentMedia != nil {
+		Audit(actor, AuditSkip, "%s - %s", CurrentMedia.Title, CurrentMedia.Artist)
+	}
+	PlayNext()
+}
+
 func Play(media *player.Media) {
 	l().Infof("prepare media %s", media.Title)
+	claimPrefetch(media)
 	err := PrepareMedia(media)
 	if err != nil {
-		l().Warn("prepare media failed. try play next")
-		PlayNext()
-		return
+		fallback := FindFallbackMedia(media)
+		if fallback == nil {
+			l().Warn("prepare media failed. try play next")
+			PlayNext()
+			return
+		}
+		media = fallback
 	}
 	CurrentMedia = media
-	AddToHistory(media)
 	if err := MainPlayer.Play(media); err != nil {
 		l().Warn("play failed", err)
 		return
@@ -37,44 +54,147 @@ func Play(media *player.Media) {
 	CurrentLyric.Reload(media.Lyric)
 	// reset
 	media.Url = ""
+	go Prefetch()
 }
 
-func Add(keyword string, user interface{}) {
-	media := MediaMatch(keyword)
-	if media == nil {
-		medias, err := Search(keyword)
-		if err != nil {
-			l().Warnf("search for %s, got error %s", keyword, err)
-			return
+// checkRequester return error if the requester is not allowed to request
+func checkRequester(user interface{}) error {
+	if u, ok := user.(*liveclient.DanmuUser); ok {
+		if r := Blacklist.CheckUser(u.Uid); r != nil {
+			l().Infof("request from %s(%s) rejected by blacklist rule %s", u.Username, u.Uid, r)
+			return &BlacklistError{Rule: r}
 		}
-		if len(medias) == 0 {
-			l().Info("search for %s, got no result", keyword)
-			return
+	}
+	return nil
+}
+
+// ParseRequestKeyword split keyword in format "title - artist", artist is empty if not specified
+func ParseRequestKeyword(keyword string) (title string, artist string) {
+	keyword = strings.TrimSpace(keyword)
+	index := strings.LastIndex(keyword, " - ")
+	if index < 0 {
+		return keyword, ""
+	}
+	title = strings.TrimSpace(keyword[:index])
+	artist = strings.TrimSpace(keyword[index+3:])
+	if title == "" || artist == "" {
+		return keyword, ""
+	}
+	return title, artist
+}
+
+// SearchRequest search medias for request keyword, if keyword is in format "title - artist",
+// only medias of the artist are returned. empty pname means searching all providers.
+func SearchRequest(keyword string, pname string) ([]*player.Media, error) {
+	title, artist := ParseRequestKeyword(keyword)
+	if artist != "" {
+		keyword = title + " " + artist
+	}
+	var medias []*player.Media
+	var err error
+	if pname == "" {
+		medias, err = Search(keyword)
+	} else {
+		medias, err = provider.Search(pname, keyword)
+	}
+	if err != nil {
+		l().Warnf("search for %s, got error %s", keyword, err)
+		return nil, err
+	}
+	if artist != "" {
+		filtered := make([]*player.Media, 0, len(medias))
+		for _, m := range medias {
+			if strings.Contains(strings.ToLower(m.Artist), strings.ToLower(artist)) {
+				filtered = append(filtered, m)
+			}
 		}
-		media = medias[0]
+		medias = filtered
 	}
+	if len(medias) == 0 {
+		l().Infof("search for %s, got no result", keyword)
+		return nil, ErrorNoResult
+	}
+	return medias, nil
+}
+
+// CheckRequest set requester of the media and check it against blacklist
+func CheckRequest(media *player.Media, user interface{}) error {
 	media.User = user
-	l().Infof("add media %s (%s)", media.Title, media.Artist)
-	UserPlaylist.Insert(-1, media)
+	if u, ok := user.(*liveclient.DanmuUser); ok {
+		if media.Title == "" {
+			_ = provider.UpdateMedia(media)
+		}
+		if err := Blacklist.CheckRequest(media, u.Uid); err != nil {
+			l().Infof("request %s from %s(%s) rejected: %s", media.Title, u.Username, u.Uid, err)
+			return err
+		}
+	}
+	return nil
 }
 
-func AddWithProvider(keyword string, pname string, user interface{}) {
-	media := provider.MatchMedia(pname, keyword)
+// ResolveRequest find the media for keyword and check it against blacklist,
+// empty pname means searching all providers.
+func ResolveRequest(keyword string, pname string, user interface{}) (*player.Media, error) {
+	if err := checkRequester(user); err != nil {
+		return nil, err
+	}
+	var media *player.Media
+	if pname == "" {
+		media = MediaMatch(keyword)
+	} else {
+		media = provider.MatchMedia(pname, keyword)
+	}
 	if media == nil {
-		medias, err := provider.Search(pname, keyword)
+		medias, err := SearchRequest(keyword, pname)
 		if err != nil {
-			l().Warnf("search for %s, got error %s", keyword, err)
-			return
-		}
-		if len(medias) == 0 {
-			l().Infof("search for %s, got no result", keyword)
-			return
+			return nil, err
 		}
 		media = medias[0]
 	}
-	media.User = user
-	l().Infof("add media %s (%s)", media.Title, media.Artist)
-	UserPlaylist.Insert(-1, media)
+	if err := CheckRequest(media, user); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+func Add(keyword string, user interface{}) error {
+	_, err := AddWithProvider(keyword, "", user)
+	return err
+}
+
+// AddWithProvider add the media found by keyword to UserPlaylist and return it,
+// empty pname means searching all providers.
+func AddWithProvider(keyword string, pname string, user interface{}) (*player.Media, error) {
+	return AddWithPriority(keyword, pname, user, 0)
+}
+
+// AddWithPriority is same as AddWithProvider, but the media is placed before medias with lower priority.
+func AddWithPriority(keyword string, pname string, user interface{}, priority int) (*player.Media, error) {
+	media, err := ResolveRequest(keyword, pname, user)
+	if err != nil {
+		return nil, err
+	}
+	AddRequest(media, priority)
+	return media, nil
+}
+
+// AddRequest add media which has passed CheckRequest to UserPlaylist
+func AddRequest(media *player.Media, priority int) {
+	media.Priority = priority
+	l().Infof("add media %s (%s) with priority %d", media.Title, media.Artist, priority)
+	Audit(requestActor(media.User), AuditQueueAdd, "%s - %s (priority %d)", media.Title, media.Artist, priority)
+	insertRequest(media)
+}
+
+// insertRequest insert media into UserPlaylist after all medias with same or higher priority
+func insertRequest(media *player.Media) {
+	if media.Priority <= 0 {
+		UserPlaylist.Insert(-1, media)
+		return
+	}
+	UserPlaylist.InsertBefore(media, func(m *player.Media) bool {
+		return m.Priority < media.Priority
+	})
 }
 
 func Seek(position float64, absolute bool) {
@@ -107,6 +227,7 @@ func SetVolume(volume float64) {
 }
 
 func Destroy() {
+	Stats.Finish(true)
 	MainPlayer.Stop()
 }
 
@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"testing"
+)
+
+func TestParseRequestKeyword(t *testing.T) {
+	for _, c := range []struct {
+		keyword, title, artist string
+	}{
+		{"晴天", "晴天", ""},
+		{"晴天 - 周杰伦", "晴天", "周杰伦"},
+		{" Stay - Alive - Mili ", "Stay - Alive", "Mili"},
+		{"a-b", "a-b", ""},
+		{" - 周杰伦", "- 周杰伦", ""},
+	} {
+		title, artist := ParseRequestKeyword(c.keyword)
+		if title != c.title || artist != c.artist {
+			t.Fatalf("%s: got (%s, %s), want (%s, %s)", c.keyword, title, artist, c.title, c.artist)
+		}
+	}
+}
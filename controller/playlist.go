@@ -2,18 +2,6 @@ package controller
 
 import "AynaLivePlayer/player"
 
-func AddToHistory(media *player.Media) {
-	l().Tracef("add media %s (%s) to history", media.Title, media.Artist)
-	media = media.Copy()
-	// reset url for future use
-	media.Url = ""
-	if History.Size() >= 1024 {
-		History.Replace([]*player.Media{})
-	}
-	History.Push(media)
-	return
-}
-
 func ToHistoryMedia(media *player.Media) *player.Media {
 	media = media.Copy()
 	media.User = HistoryUser
@@ -0,0 +1,332 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PointsPath is the transaction log of points, one json transaction per line.
+// balances are restored by replaying the log.
+const PointsPath = "./points.jsonl"
+
+// number of users shown by leaderboard command
+const pointsRankSize = 5
+
+var ErrorNotEnoughPoints = errors.New("not enough points")
+
+type PointsTransaction struct {
+	Time     int64
+	Uid      string
+	Username string
+	// Amount is positive when user earns points, negative when user spends points
+	Amount int
+	// Balance is the balance after the transaction
+	Balance int
+	Reason  string
+}
+
+type PointsAccount struct {
+	Uid      string
+	Username string
+	Balance  int
+	// LastChat is the last time user earned points by chatting
+	LastChat int64 `json:"-"`
+}
+
+type PointsStore struct {
+	Accounts map[string]*PointsAccount
+	filename string
+	lock     sync.RWMutex
+}
+
+var Points *PointsStore
+
+func NewPointsStore(filename string) *PointsStore {
+	s := &PointsStore{
+		Accounts: make(map[string]*PointsAccount),
+		filename: filename,
+	}
+	err := util.LoadJsonLines(filename, func(line []byte) {
+		var t PointsTransaction
+		if err := json.Unmarshal(line, &t); err != nil {
+			l().Warnf("skip invalid points transaction: %s", err)
+			return
+		}
+		s.account(t.Uid, t.Username).Balance = t.Balance
+	})
+	if err != nil {
+		l().Infof("load points from %s failed: %s", filename, err)
+	}
+	return s
+}
+
+// account return the account of uid, create one if not exists. caller should hold the lock.
+func (s *PointsStore) account(uid string, username string) *PointsAccount {
+	a, ok := s.Accounts[uid]
+	if !ok {
+		a = &PointsAccount{Uid: uid}
+		s.Accounts[uid] = a
+	}
+	if username != "" {
+		a.Username = username
+	}
+	return a
+}
+
+func (s *PointsStore) Balance(uid string) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if a, ok := s.Accounts[uid]; ok {
+		return a.Balance
+	}
+	return 0
+}
+
+// FindAccount find account by uid or username, nil if not exists
+func (s *PointsStore) FindAccount(name string) *PointsAccount {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if a, ok := s.Accounts[name]; ok {
+		return a
+	}
+	for _, a := range s.Accounts {
+		if a.Username == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// record change balance and append the transaction to log. caller should hold the lock.
+func (s *PointsStore) record(a *PointsAccount, amount int, reason string) int {
+	a.Balance += amount
+	t := &PointsTransaction{
+		Time:     time.Now().Unix(),
+		Uid:      a.Uid,
+		Username: a.Username,
+		Amount:   amount,
+		Balance:  a.Balance,
+		Reason:   reason,
+	}
+	l().Debugf("points of %s(%s) changed %d by %s, balance %d", a.Username, a.Uid, amount, reason, a.Balance)
+	if err := util.AppendJsonLine(s.filename, t); err != nil {
+		l().Warnf("write points transaction to %s failed: %s", s.filename, err)
+	}
+	return a.Balance
+}
+
+// Add add amount (can be negative) to the balance of user and return new balance
+func (s *PointsStore) Add(uid string, username string, amount int, reason string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.record(s.account(uid, username), amount, reason)
+}
+
+// Spend deduct amount from balance of user, return ErrorNotEnoughPoints if balance is not enough
+func (s *PointsStore) Spend(uid string, username string, amount int, reason string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	a := s.account(uid, username)
+	if a.Balance < amount {
+		return ErrorNotEnoughPoints
+	}
+	s.record(a, -amount, reason)
+	return nil
+}
+
+// earnChat add chat points if user has not earned in interval
+func (s *PointsStore) earnChat(user *liveclient.DanmuUser, amount int, interval int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	a := s.account(user.Uid, user.Username)
+	now := time.Now().Unix()
+	if now-a.LastChat < interval {
+		return
+	}
+	a.LastChat = now
+	s.record(a, amount, "chat")
+}
+
+// Top return n accounts with highest balance, n <= 0 means all
+func (s *PointsStore) Top(n int) []PointsAccount {
+	s.lock.RLock()
+	accounts := make([]PointsAccount, 0, len(s.Accounts))
+	for _, a := range s.Accounts {
+		if a.Balance > 0 {
+			accounts = append(accounts, *a)
+		}
+	}
+	s.lock.RUnlock()
+	sort.SliceStable(accounts, func(i, j int) bool {
+		if accounts[i].Balance != accounts[j].Balance {
+			return accounts[i].Balance > accounts[j].Balance
+		}
+		return accounts[i].Uid < accounts[j].Uid
+	})
+	if n > 0 && len(accounts) > n {
+		accounts = accounts[:n]
+	}
+	return accounts
+}
+
+// CommandPrice return points needed to run the command, 0 means free
+func CommandPrice(name string) int {
+	c := config.Points
+	for i, cmd := range c.PriceCommands {
+		if cmd == name && i < len(c.Prices) {
+			return c.Prices[i]
+		}
+	}
+	return 0
+}
+
+// SetCommandPrice change price of the command in config
+func SetCommandPrice(name string, price int) {
+	c := config.Points
+	for len(c.Prices) < len(c.PriceCommands) {
+		c.Prices = append(c.Prices, 0)
+	}
+	for i, cmd := range c.PriceCommands {
+		if cmd == name {
+			c.Prices[i] = price
+			return
+		}
+	}
+	c.PriceCommands = append(c.PriceCommands, name)
+	c.Prices = append(c.Prices, price)
+}
+
+type pointsDanmuHandler struct{}
+
+func (h pointsDanmuHandler) Execute(danmu *liveclient.DanmuMessage) {
+	c := config.Points
+	if !c.Enable || c.ChatPoints <= 0 {
+		return
+	}
+	amount := c.ChatPoints
+	if HasRole(&danmu.User, RolePrivilege) {
+		amount += c.GuardChatBonus
+	}
+	Points.earnChat(&danmu.User, amount, int64(c.ChatInterval))
+}
+
+// earnPaidPoints give points for gifts and guard purchases
+func earnPaidPoints(user *liveclient.DanmuUser, value float64, currency liveclient.Currency, reason string) {
+	if !config.Points.Enable || currency != liveclient.CurrencyCNY {
+		return
+	}
+	if amount := int(value * float64(config.Points.PointsPerYuan)); amount > 0 {
+		Points.Add(user.Uid, user.Username, amount, reason)
+	}
+}
+
+func handlePointsGift(event *event.Event) {
+	msg := event.Data.(*liveclient.GiftMessage)
+	earnPaidPoints(&msg.User, msg.Value, msg.Currency, "gift "+msg.GiftName)
+}
+
+func handlePointsGuard(event *event.Event) {
+	msg := event.Data.(*liveclient.GuardMessage)
+	earnPaidPoints(&msg.User, msg.Value, msg.Currency, "guard "+msg.Name)
+}
+
+var (
+	PointsBalanceCommand = &Command{
+		Name:         "积分",
+		Aliases:      []string{"points"},
+		Description:  "查看自己的积分",
+		Permission:   PermissionEveryone,
+		UserCooldown: 10,
+		Execute: func(ctx *CommandContext) error {
+			if !config.Points.Enable {
+				return nil
+			}
+			ctx.ReplyTemplate(config.Points.ReplyBalance,
+				map[string]interface{}{"balance": Points.Balance(ctx.User().Uid)})
+			return nil
+		},
+	}
+	PointsRankCommand = &Command{
+		Name:           "积分排行",
+		Aliases:        []string{"rank"},
+		Description:    "查看积分排行",
+		Permission:     PermissionEveryone,
+		GlobalCooldown: 30,
+		Execute: func(ctx *CommandContext) error {
+			if !config.Points.Enable {
+				return nil
+			}
+			ranks := make([]string, 0, pointsRankSize)
+			for i, a := range Points.Top(pointsRankSize) {
+				ranks = append(ranks, fmt.Sprintf("%d.%s(%d)", i+1, a.Username, a.Balance))
+			}
+			ctx.Reply(strings.Join(ranks, " "))
+			return nil
+		},
+	}
+	// PointsPriorityCommand move the latest request of user before normal requests, it has a price by default
+	PointsPriorityCommand = &Command{
+		Name:        "积分插队",
+		Description: "用积分把自己最后点的歌移到普通点歌之前",
+		Permission:  PermissionEveryone,
+		Execute: func(ctx *CommandContext) error {
+			if !config.Points.Enable {
+				return nil
+			}
+			if PrioritizeRequest(ctx.User().Uid, PriorityPaid, ctx.Actor()) == nil {
+				return ErrorNoResult
+			}
+			return nil
+		},
+	}
+	PointsGrantCommand = &Command{
+		Name:        "加积分",
+		Description: "给用户增加积分",
+		Args:        []CommandArg{{Name: "user", Required: true}, {Name: "amount", Required: true}},
+		Permission:  PermissionAdmin,
+		Execute: func(ctx *CommandContext) error {
+			return adjustPoints(ctx, 1)
+		},
+	}
+	PointsDeductCommand = &Command{
+		Name:        "扣积分",
+		Description: "扣除用户的积分",
+		Args:        []CommandArg{{Name: "user", Required: true}, {Name: "amount", Required: true}},
+		Permission:  PermissionAdmin,
+		Execute: func(ctx *CommandContext) error {
+			return adjustPoints(ctx, -1)
+		},
+	}
+)
+
+// adjustPoints change points of the user in arguments by admin, user is uid or username
+func adjustPoints(ctx *CommandContext, sign int) error {
+	amount, err := strconv.Atoi(ctx.Arg("amount"))
+	if err != nil || amount <= 0 {
+		return ErrorCommandArgs
+	}
+	uid, username := ctx.Arg("user"), ""
+	if a := Points.FindAccount(uid); a != nil {
+		uid, username = a.Uid, a.Username
+	} else if _, err := strconv.Atoi(uid); err != nil {
+		l().Infof("points account %s not found", uid)
+		return ErrorCommandArgs
+	}
+	balance := Points.Add(uid, username, sign*amount, "admin "+ctx.User().Username)
+	ctx.Reply(fmt.Sprintf("%s: %d", util.GetOrDefault(username, uid), balance))
+	return nil
+}
+
+func init() {
+	RegisterCommand(PointsBalanceCommand, PointsRankCommand, PointsPriorityCommand, PointsGrantCommand, PointsDeductCommand)
+}
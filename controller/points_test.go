@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPointsStore(t *testing.T) {
+	filename := filepath.Join(os.TempDir(), "points_test.jsonl")
+	_ = os.Remove(filename)
+	defer os.Remove(filename)
+	s := NewPointsStore(filename)
+	s.Add("1", "user1", 100, "test")
+	s.Add("2", "user2", 30, "test")
+	if s.Spend("2", "user2", 50, "test") != ErrorNotEnoughPoints {
+		t.Fatal("spend more than balance should fail")
+	}
+	if err := s.Spend("1", "user1", 40, "test"); err != nil || s.Balance("1") != 60 {
+		t.Fatal("spend failed")
+	}
+	fmt.Println(s.Top(10))
+	if top := s.Top(1); len(top) != 1 || top[0].Uid != "1" {
+		t.Fatal("user1 should be the first")
+	}
+	if s.FindAccount("user2") == nil {
+		t.Fatal("account should be found by username")
+	}
+	s2 := NewPointsStore(filename)
+	if s2.Balance("1") != 60 || s2.Balance("2") != 30 {
+		t.Fatal("balance should be restored from transaction log")
+	}
+}
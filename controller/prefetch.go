@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/player"
+	"sync"
+	"time"
+)
+
+// prefetchWaitTimeout is the time after which Play warns about an unfinished prefetch
+const prefetchWaitTimeout = 10 * time.Second
+
+type prefetchEntry struct {
+	done       chan struct{}
+	resolvedAt time.Time
+	err        error
+	// stale means the media is no longer a candidate of next media, it is evicted once resolved
+	stale bool
+}
+
+var prefetched = make(map[*player.Media]*prefetchEntry)
+var prefetchedSystem *player.Media
+var prefetchLock sync.Mutex
+
+func handlePrefetchQueueUpdate(event *event.Event) {
+	Prefetch()
+}
+
+func handlePrefetchSystemUpdate(event *event.Event) {
+	// system playlist has been rebuilt, keep the picked media unless it is gone,
+	// otherwise the picked one is skipped since its playlist has moved to the next.
+	prefetchLock.Lock()
+	if prefetchedSystem != nil && !inSystemPlaylist(prefetchedSystem) {
+		l().Debugf("picked system media %s is removed from system playlist", prefetchedSystem.Title)
+		prefetchedSystem = nil
+	}
+	prefetchLock.Unlock()
+	Prefetch()
+}
+
+func inSystemPlaylist(media *player.Media) bool {
+	SystemPlaylist.Lock.RLock()
+	defer SystemPlaylist.Lock.RUnlock()
+	for _, m := range SystemPlaylist.Playlist {
+		if m == media {
+			return true
+		}
+	}
+	return false
+}
+
+// prefetchCandidates return next few medias that are going to be played
+func prefetchCandidates() []*player.Media {
+	count := config.Player.PrefetchCount
+	candidates := make([]*player.Media, 0, count)
+	if UserPlaylist.Config.RandomNext {
+		// next user media is unpredictable, but it still blocks system playlist
+		if UserPlaylist.Size() > 0 {
+			return candidates
+		}
+	} else {
+		UserPlaylist.Lock.RLock()
+		for i := 0; i < len(UserPlaylist.Playlist) && len(candidates) < count; i++ {
+			candidates = append(candidates, UserPlaylist.Playlist[i])
+		}
+		UserPlaylist.Lock.RUnlock()
+	}
+	if len(candidates) < count && UserPlaylist.Size() < count && SystemPlaylist.Size() > 0 {
+		prefetchLock.Lock()
+		if prefetchedSystem == nil {
+			prefetchedSystem = pickSystemMedia()
+		}
+		if prefetchedSystem != nil {
+			candidates = append(candidates, prefetchedSystem)
+		}
+		prefetchLock.Unlock()
+	}
+	return candidates
+}
+
+// takePrefetchedSystemMedia return the system media picked by prefetcher, or nil if not exists.
+func takePrefetchedSystemMedia() *player.Media {
+	prefetchLock.Lock()
+	defer prefetchLock.Unlock()
+	m := prefetchedSystem
+	prefetchedSystem = nil
+	return m
+}
+
+func isPrefetchExpired(entry *prefetchEntry) bool {
+	if entry.resolvedAt.IsZero() {
+		return false
+	}
+	return time.Since(entry.resolvedAt) > time.Duration(config.Player.PrefetchUrlTTL)*time.Second
+}
+
+// Prefetch resolve info, url and lyric of next few medias in background
+func Prefetch() {
+	if config.Player.PrefetchCount <= 0 {
+		return
+	}
+	candidates := prefetchCandidates()
+	isCandidate := make(map[*player.Media]bool)
+	for _, m := range candidates {
+		isCandidate[m] = true
+	}
+	prefetchLock.Lock()
+	defer prefetchLock.Unlock()
+	// medias removed or moved away from next few are evicted, unfinished ones are evicted when resolved
+	for m, entry := range prefetched {
+		if isCandidate[m] {
+			entry.stale = false
+			continue
+		}
+		entry.stale = true
+		select {
+		case <-entry.done:
+			evictPrefetch(m)
+		default:
+		}
+	}
+	for _, m := range candidates {
+		if entry, ok := prefetched[m]; ok {
+			select {
+			case <-entry.done:
+				if !isPrefetchExpired(entry) && entry.err == nil {
+					continue
+				}
+				m.Url = ""
+			default:
+				continue
+			}
+		}
+		entry := &prefetchEntry{done: make(chan struct{})}
+		prefetched[m] = entry
+		go resolvePrefetch(m, entry)
+	}
+}
+
+func resolvePrefetch(media *player.Media, entry *prefetchEntry) {
+	l().Debugf("prefetch media %s", media.Title)
+	entry.err = PrepareMedia(media)
+	if entry.err == nil {
+		entry.resolvedAt = time.Now()
+	}
+	close(entry.done)
+	prefetchLock.Lock()
+	if entry.stale && prefetched[media] == entry {
+		evictPrefetch(media)
+	}
+	prefetchLock.Unlock()
+}
+
+// evictPrefetch remove resolved prefetch result of media, url is dropped so it is fetched again
+// when the media is played. prefetchLock must be held.
+func evictPrefetch(media *player.Media) {
+	l().Debugf("drop stale prefetch result of %s", media.Title)
+	media.Url = ""
+	delete(prefetched, media)
+}
+
+// claimPrefetch wait prefetch of the media to be finished and remove it from prefetch list,
+// expired url will be removed so PrepareMedia can fetch it again. It never returns before
+// the prefetch finishes, otherwise PrepareMedia would race with it on the same media.
+func claimPrefetch(media *player.Media) {
+	prefetchLock.Lock()
+	entry, ok := prefetched[media]
+	delete(prefetched, media)
+	prefetchLock.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case <-entry.done:
+	case <-time.After(prefetchWaitTimeout):
+		// prefetch is still writing to the media, it can't be prepared again until finished
+		l().Warnf("prefetch of %s takes more than %s, keep waiting", media.Title, prefetchWaitTimeout)
+		<-entry.done
+	}
+	if isPrefetchExpired(entry) {
+		l().Infof("prefetched url of %s expired, fetch again", media.Title)
+		media.Url = ""
+	}
+}
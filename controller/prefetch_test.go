@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/player"
+	"testing"
+	"time"
+)
+
+// newResolvedMedia return a media which does not need to fetch anything when prepared
+func newResolvedMedia(title string) *player.Media {
+	return &player.Media{Title: title, Cover: player.Picture{Url: "cover"}, Url: "url", Lyric: "lyric"}
+}
+
+func TestPrefetch_Evict(t *testing.T) {
+	defer func(count int) { config.Player.PrefetchCount = count }(config.Player.PrefetchCount)
+	config.Player.PrefetchCount = 2
+	UserPlaylist = player.NewPlaylist("user", player.PlaylistConfig{})
+	SystemPlaylist = player.NewPlaylist("system", player.PlaylistConfig{})
+	a, b := newResolvedMedia("a"), newResolvedMedia("b")
+	UserPlaylist.Push(a)
+	UserPlaylist.Push(b)
+	Prefetch()
+	// b is removed from queue while being prefetched
+	UserPlaylist.DeleteMedia(b)
+	Prefetch()
+	deadline := time.Now().Add(time.Second)
+	for {
+		prefetchLock.Lock()
+		_, okA := prefetched[a]
+		_, okB := prefetched[b]
+		prefetchLock.Unlock()
+		if !okA {
+			t.Fatal("candidate should be kept")
+		}
+		if !okB {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("removed media is not evicted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if b.Url != "" {
+		t.Fatal("url of evicted media should be dropped")
+	}
+	claimPrefetch(a)
+	if a.Url != "url" {
+		t.Fatal("prefetched url should be kept for claimed media")
+	}
+}
+
+func TestPrefetch_KeepSystemPick(t *testing.T) {
+	defer func(count int) { config.Player.PrefetchCount = count }(config.Player.PrefetchCount)
+	config.Player.PrefetchCount = 0
+	SystemPlaylist = player.NewPlaylist("system", player.PlaylistConfig{})
+	a, b := newResolvedMedia("a"), newResolvedMedia("b")
+	SystemPlaylist.Replace([]*player.Media{a, b})
+	prefetchedSystem = b
+	handlePrefetchSystemUpdate(nil)
+	if prefetchedSystem != b {
+		t.Fatal("picked media should be kept when system playlist is rebuilt")
+	}
+	SystemPlaylist.Replace([]*player.Media{a})
+	handlePrefetchSystemUpdate(nil)
+	if takePrefetchedSystemMedia() != nil {
+		t.Fatal("picked media removed from system playlist should be dropped")
+	}
+}
@@ -49,23 +49,6 @@ func MediaMatch(keyword string) *player.Media {
 	return nil
 }
 
-func Search(keyword string) ([]*player.Media, error) {
-	l().Infof("Search for %s", keyword)
-	for _, p := range config.Provider.Priority {
-		if pr, ok := provider.Providers[p]; ok {
-			r, err := pr.Search(keyword)
-			if err != nil {
-				l().Warn("Provider %s return err", err)
-				continue
-			}
-			return r, err
-		} else {
-			l().Warnf("Provider %s not exist", p)
-		}
-	}
-	return nil, provider.ErrorNoSuchProvider
-}
-
 func SearchWithProvider(keyword string, p string) ([]*player.Media, error) {
 	l().Infof("Search for %s using %s", keyword, p)
 	if pr, ok := provider.Providers[p]; ok {
@@ -0,0 +1,138 @@
+package controller
+
+import "AynaLivePlayer/player"
+
+// priorities of requests, see player.Media.Priority
+const (
+	PriorityNormal = iota
+	// PriorityPaid requests are placed before normal requests
+	PriorityPaid
+	// PriorityJump requests are placed at the top of queue
+	PriorityJump
+)
+
+// UserRequests return positions (starting from 0) and medias requested by the user in playlist
+func UserRequests(playlist *player.Playlist, uid string) ([]int, []*player.Media) {
+	playlist.Lock.RLock()
+	defer playlist.Lock.RUnlock()
+	positions := make([]int, 0)
+	medias := make([]*player.Media, 0)
+	for i, m := range playlist.Playlist {
+		if u := m.DanmuUser(); u != nil && u.Uid == uid {
+			positions = append(positions, i)
+			medias = append(medias, m)
+		}
+	}
+	return positions, medias
+}
+
+// CancelRequest remove the latest request of the user, requests in UserPlaylist are
+// cancelled before those waiting for approval. return nil if user has no request.
+func CancelRequest(uid string, actor AuditActor) *player.Media {
+	// playlist might be changed after UserRequests, so medias are deleted by identity
+	if _, medias := UserRequests(UserPlaylist, uid); len(medias) > 0 {
+		media := medias[len(medias)-1]
+		if UserPlaylist.DeleteMedia(media) {
+			l().Infof("user %s cancel request %s", uid, media.Title)
+			Audit(actor, AuditQueueDelete, "%s - %s", media.Title, media.Artist)
+			return media
+		}
+	}
+	if _, medias := UserRequests(PendingPlaylist, uid); len(medias) > 0 {
+		media := medias[len(medias)-1]
+		if takePendingMedia(media) {
+			l().Infof("user %s cancel pending request %s", uid, media.Title)
+			Audit(actor, AuditQueueDelete, "%s - %s (pending)", media.Title, media.Artist)
+			return media
+		}
+	}
+	return nil
+}
+
+// RemoveUserRequests remove all requests of the user in UserPlaylist and PendingPlaylist,
+// return number of removed requests.
+func RemoveUserRequests(uid string, actor AuditActor) int {
+	// playlist might be changed after UserRequests, so medias are deleted by identity
+	cnt := 0
+	_, medias := UserRequests(UserPlaylist, uid)
+	for _, m := range medias {
+		if UserPlaylist.DeleteMedia(m) {
+			cnt++
+		}
+	}
+	_, medias = UserRequests(PendingPlaylist, uid)
+	for _, m := range medias {
+		if takePendingMedia(m) {
+			cnt++
+		}
+	}
+	l().Infof("remove %d requests of user %s", cnt, uid)
+	Audit(actor, AuditQueueDelete, "%d requests of user %s", cnt, uid)
+	return cnt
+}
+
+// userPlaylistAt return media at index of UserPlaylist, nil if not exists
+func userPlaylistAt(index int) *player.Media {
+	UserPlaylist.Lock.RLock()
+	defer UserPlaylist.Lock.RUnlock()
+	if index < 0 || index >= len(UserPlaylist.Playlist) {
+		return nil
+	}
+	return UserPlaylist.Playlist[index]
+}
+
+// BumpRequest move media at index to the top of UserPlaylist and return it, nil if not exists
+func BumpRequest(index int, actor AuditActor) *player.Media {
+	media := userPlaylistAt(index)
+	// media is moved by identity, in case the playlist is changed after it is found
+	if media == nil || !UserPlaylist.MoveMedia(media, 0) {
+		l().Warnf("bump request failed, media at index %d does not exist", index)
+		return nil
+	}
+	Audit(actor, AuditQueueMove, "%s - %s from %d to top", media.Title, media.Artist, index+1)
+	return media
+}
+
+// RemoveRequest remove media at index from UserPlaylist and return it, nil if not exists
+func RemoveRequest(index int, actor AuditActor) *player.Media {
+	media := UserPlaylist.Remove(index)
+	if media == nil {
+		l().Warnf("remove request failed, media at index %d does not exist", index)
+		return nil
+	}
+	Audit(actor, AuditQueueDelete, "%s - %s at %d", media.Title, media.Artist, index+1)
+	return media
+}
+
+// RequestPosition return index of the media in UserPlaylist, -1 if not exists
+func RequestPosition(media *player.Media) int {
+	UserPlaylist.Lock.RLock()
+	defer UserPlaylist.Lock.RUnlock()
+	for i, m := range UserPlaylist.Playlist {
+		if m == media {
+			return i
+		}
+	}
+	return -1
+}
+
+// PrioritizeRequest raise the priority of latest request of the user in UserPlaylist and
+// move it before requests with lower priority. return nil if user has no request.
+func PrioritizeRequest(uid string, priority int, actor AuditActor) *player.Media {
+	_, medias := UserRequests(UserPlaylist, uid)
+	if len(medias) == 0 {
+		return nil
+	}
+	media := medias[len(medias)-1]
+	if media.Priority >= priority {
+		return media
+	}
+	if !UserPlaylist.DeleteMedia(media) {
+		return nil
+	}
+	l().Infof("user %s raise priority of %s to %d", uid, media.Title, priority)
+	media.Priority = priority
+	insertRequest(media)
+	Audit(actor, AuditQueueMove, "%s - %s to priority %d", media.Title, media.Artist, priority)
+	return media
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/player"
+	"fmt"
+	"testing"
+)
+
+func newTestQueue() []*player.Media {
+	UserPlaylist = player.NewPlaylist("user", player.PlaylistConfig{})
+	PendingPlaylist = player.NewPlaylist("pending", player.PlaylistConfig{})
+	medias := make([]*player.Media, 0)
+	for i, uid := range []string{"1", "2", "1", "3"} {
+		m := &player.Media{Title: fmt.Sprintf("song%d", i), User: &liveclient.DanmuUser{Uid: uid}}
+		medias = append(medias, m)
+		UserPlaylist.Push(m)
+	}
+	return medias
+}
+
+func TestUserRequests(t *testing.T) {
+	medias := newTestQueue()
+	positions, requests := UserRequests(UserPlaylist, "1")
+	fmt.Println(positions)
+	if len(positions) != 2 || positions[1] != 2 || requests[1] != medias[2] {
+		t.Fatal("requests of user are not found")
+	}
+}
+
+func TestCancelRequest(t *testing.T) {
+	medias := newTestQueue()
+	pending := &player.Media{Title: "pending", User: &liveclient.DanmuUser{Uid: "3"}}
+	AddPendingRequest(pending, PriorityNormal)
+	if CancelRequest("1", ActorSystem) != medias[2] || UserPlaylist.Size() != 3 {
+		t.Fatal("latest request of user should be cancelled")
+	}
+	// requests in user playlist are cancelled before pending ones
+	if CancelRequest("3", ActorSystem) != medias[3] || CancelRequest("3", ActorSystem) != pending {
+		t.Fatal("pending request should be cancelled last")
+	}
+	if PendingPlaylist.Size() != 0 || CancelRequest("3", ActorSystem) != nil {
+		t.Fatal("user has no request left")
+	}
+}
+
+func TestRemoveRequest(t *testing.T) {
+	medias := newTestQueue()
+	if RemoveRequest(1, ActorSystem) != medias[1] || RemoveRequest(10, ActorSystem) != nil {
+		t.Fatal("media at index should be removed")
+	}
+	if BumpRequest(2, ActorSystem) != medias[3] || UserPlaylist.Playlist[0] != medias[3] {
+		t.Fatal("media should be moved to top")
+	}
+	if UserPlaylist.Size() != 3 || UserPlaylist.Playlist[1] != medias[0] || UserPlaylist.Playlist[2] != medias[2] {
+		t.Fatal("other medias should keep their order")
+	}
+}
+
+func TestRemoveUserRequests(t *testing.T) {
+	medias := newTestQueue()
+	AddPendingRequest(&player.Media{Title: "pending", User: &liveclient.DanmuUser{Uid: "1"}}, PriorityNormal)
+	if RemoveUserRequests("1", ActorSystem) != 3 {
+		t.Fatal("all requests of user should be removed")
+	}
+	if UserPlaylist.Size() != 2 || UserPlaylist.Playlist[0] != medias[1] || PendingPlaylist.Size() != 0 {
+		t.Fatal("requests of other users should be kept")
+	}
+}
+
+func TestApproveMedia(t *testing.T) {
+	newTestQueue()
+	a := &player.Media{Title: "a", User: &liveclient.DanmuUser{Uid: "1"}}
+	b := &player.Media{Title: "b", User: &liveclient.DanmuUser{Uid: "2"}}
+	AddPendingRequest(a, PriorityNormal)
+	AddPendingRequest(b, PriorityNormal)
+	// a is removed before b is approved, b is at index 0 now
+	if !RejectMedia(a, ActorGUI) || RejectMedia(a, ActorGUI) {
+		t.Fatal("pending media should be rejected once")
+	}
+	if !ApproveMedia(b, ActorGUI) || PendingPlaylist.Size() != 0 {
+		t.Fatal("pending media should be approved")
+	}
+	if UserPlaylist.Size() != 5 || UserPlaylist.Playlist[4] != b || b.Approver != ActorGUI.String() {
+		t.Fatal("approved media should be added to user playlist")
+	}
+}
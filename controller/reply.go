@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/liveclient"
+	"fmt"
+	"strings"
+)
+
+// FormatReply replace {key} in template with values, unknown keys are kept as is.
+func FormatReply(template string, values map[string]interface{}) string {
+	pairs := make([]string, 0, len(values)*2)
+	for k, v := range values {
+		pairs = append(pairs, "{"+k+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// replyQueueSize is the maximum number of messages waiting to be sent,
+// new messages are dropped when the queue is full.
+const replyQueueSize = 10
+
+type pendingReply struct {
+	sender  liveclient.MessageSender
+	message string
+}
+
+var replyQueue = make(chan pendingReply, replyQueueSize)
+
+func init() {
+	go sendReplyLoop()
+}
+
+// sendReplyLoop send queued messages one by one, sender blocks to keep message interval
+func sendReplyLoop() {
+	for r := range replyQueue {
+		if err := r.sender.SendMessage(r.message); err != nil {
+			l().Warnf("send message %s failed: %s", r.message, err)
+		}
+	}
+}
+
+// SendMessage queue message to be sent to live room if replying is enabled
+// and current live client supports sending messages.
+func SendMessage(message string) {
+	if !config.LiveRoom.SendReply || message == "" {
+		return
+	}
+	sender, ok := LiveClient.(liveclient.MessageSender)
+	if !ok {
+		l().Debugf("live client does not support sending message, ignore %s", message)
+		return
+	}
+	select {
+	case replyQueue <- pendingReply{sender: sender, message: message}:
+	default:
+		l().Warnf("too many messages waiting to be sent, drop %s", message)
+	}
+}
+
+// Reply send a message to the user
+func Reply(user *liveclient.DanmuUser, msg string) {
+	l().Infof("reply to %s(%s): %s", user.Username, user.Uid, msg)
+	SendMessage(msg)
+}
+
+// ReplyTemplate format the template and reply to the user, nothing is sent if template is empty.
+// {user} is always available in the template.
+func ReplyTemplate(user *liveclient.DanmuUser, template string, values map[string]interface{}) {
+	if template == "" {
+		return
+	}
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	values["user"] = user.Username
+	Reply(user, FormatReply(template, values))
+}
@@ -0,0 +1,232 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/util"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// built-in roles, they can not be redefined in config
+const (
+	// RoleUser is everyone
+	RoleUser = "user"
+	// RolePrivilege is users with any guard level
+	RolePrivilege = "privilege"
+	// RoleAdmin is room admins
+	RoleAdmin = "admin"
+)
+
+var BuiltinRoles = []string{RoleUser, RolePrivilege, RoleAdmin}
+
+var ErrorInvalidRole = errors.New("role name is empty or built-in")
+
+// Role is a named group of users, a user has the role if any rule matches
+type Role struct {
+	Name string
+	// MedalName empty means any medal, MedalLevel 0 means the medal rule is disabled if MedalName is empty
+	MedalName  string
+	MedalLevel int
+	// GuardLevel is the lowest guard level (3 captain, 2 admiral, 1 governor), 0 means disabled
+	GuardLevel int
+	Admin      bool
+	Uids       []string
+}
+
+func (r *Role) Match(user *liveclient.DanmuUser) bool {
+	if r.Admin && user.Admin {
+		return true
+	}
+	if r.GuardLevel > 0 && user.Privilege > 0 && user.Privilege <= r.GuardLevel {
+		return true
+	}
+	if r.MedalName != "" || r.MedalLevel > 0 {
+		if (r.MedalName == "" || r.MedalName == user.Medal.Name) && user.Medal.Level >= util.IntMax(r.MedalLevel, 1) {
+			return true
+		}
+	}
+	return util.StringSliceContains(r.Uids, user.Uid)
+}
+
+func (r *Role) Validate() error {
+	if strings.TrimSpace(r.Name) == "" || util.StringSliceContains(BuiltinRoles, r.Name) {
+		return ErrorInvalidRole
+	}
+	return nil
+}
+
+// builtinRole return the role of built-in name, nil if name is not built-in
+func builtinRole(name string) *Role {
+	switch name {
+	case RolePrivilege:
+		return &Role{Name: name, GuardLevel: 3}
+	case RoleAdmin:
+		return &Role{Name: name, Admin: true}
+	}
+	return nil
+}
+
+// roleCache is parsed custom roles, it is rebuilt when roles are set
+var roleCache []*Role
+var roleLock sync.RWMutex
+
+// parseRoles parse custom roles in config, missing fields are treated as empty
+// because empty values at the end of list are dropped by config file.
+func parseRoles() []*Role {
+	c := config.Role
+	result := make([]*Role, 0, len(c.Names))
+	for i, name := range c.Names {
+		r := &Role{Name: strings.TrimSpace(name)}
+		if err := r.Validate(); err != nil {
+			l().Warnf("ignore invalid role '%s'", name)
+			continue
+		}
+		if i < len(c.MedalNames) {
+			r.MedalName = c.MedalNames[i]
+		}
+		if i < len(c.MedalLevels) {
+			r.MedalLevel = c.MedalLevels[i]
+		}
+		if i < len(c.GuardLevels) {
+			r.GuardLevel = c.GuardLevels[i]
+		}
+		if i < len(c.Admins) {
+			r.Admin = c.Admins[i]
+		}
+		if i < len(c.Uids) {
+			r.Uids = parseUids(c.Uids[i])
+		}
+		result = append(result, r)
+	}
+	return result
+}
+
+// ReloadRoles parse custom roles in config again, it should be called after config is loaded
+func ReloadRoles() {
+	roleLock.Lock()
+	roleCache = parseRoles()
+	roleLock.Unlock()
+}
+
+// cachedRoles return parsed custom roles, they should not be modified
+func cachedRoles() []*Role {
+	roleLock.RLock()
+	cached := roleCache
+	roleLock.RUnlock()
+	if cached != nil {
+		return cached
+	}
+	ReloadRoles()
+	roleLock.RLock()
+	defer roleLock.RUnlock()
+	return roleCache
+}
+
+// GetRoles return copies of custom roles in config
+func GetRoles() []*Role {
+	cached := cachedRoles()
+	result := make([]*Role, len(cached))
+	for i, r := range cached {
+		role := *r
+		role.Uids = util.StringSliceCopy(r.Uids)
+		result[i] = &role
+	}
+	return result
+}
+
+// SetRoles replace custom roles in config
+func SetRoles(roles []*Role) {
+	c := config.Role
+	c.Names = make([]string, len(roles))
+	c.MedalNames = make([]string, len(roles))
+	c.MedalLevels = make([]int, len(roles))
+	c.GuardLevels = make([]int, len(roles))
+	c.Admins = make([]bool, len(roles))
+	c.Uids = make([]string, len(roles))
+	for i, r := range roles {
+		c.Names[i], c.MedalNames[i], c.MedalLevels[i] = r.Name, r.MedalName, r.MedalLevel
+		c.GuardLevels[i], c.Admins[i], c.Uids[i] = r.GuardLevel, r.Admin, strings.Join(r.Uids, ",")
+	}
+	ReloadRoles()
+}
+
+// AddRoleUid add uid to the whitelist of custom role
+func AddRoleUid(name string, uid string) error {
+	roles := GetRoles()
+	for _, r := range roles {
+		if r.Name != name {
+			continue
+		}
+		if !util.StringSliceContains(r.Uids, uid) {
+			r.Uids = append(r.Uids, uid)
+			l().Infof("add %s to whitelist of role %s", uid, name)
+			SetRoles(roles)
+		}
+		return nil
+	}
+	return ErrorInvalidRole
+}
+
+// parseUids split uid whitelist separated by comma
+func parseUids(s string) []string {
+	uids := make([]string, 0)
+	for _, uid := range strings.Split(s, ",") {
+		if uid = strings.TrimSpace(uid); uid != "" {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+// RoleNames return names of built-in and custom roles
+func RoleNames() []string {
+	names := append([]string{}, BuiltinRoles...)
+	for _, r := range cachedRoles() {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// HasRole return true if the user has the role, unknown role matches nobody
+func HasRole(user *liveclient.DanmuUser, name string) bool {
+	if name == RoleUser {
+		return true
+	}
+	if r := builtinRole(name); r != nil {
+		return r.Match(user)
+	}
+	for _, r := range cachedRoles() {
+		if r.Name == name {
+			return r.Match(user)
+		}
+	}
+	return false
+}
+
+// HasAnyRole return true if the user has one of the roles
+func HasAnyRole(user *liveclient.DanmuUser, names []string) bool {
+	for _, name := range names {
+		if HasRole(user, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// UserRoles return names of all roles the user has
+func UserRoles(user *liveclient.DanmuUser) []string {
+	names := make([]string, 0)
+	for _, name := range BuiltinRoles {
+		if HasRole(user, name) {
+			names = append(names, name)
+		}
+	}
+	for _, r := range cachedRoles() {
+		if r.Match(user) {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
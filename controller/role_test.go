@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/liveclient"
+	"fmt"
+	"testing"
+)
+
+func TestRole_Match(t *testing.T) {
+	SetRoles([]*Role{
+		{Name: "fans", MedalName: "卡西米尔", MedalLevel: 10},
+		{Name: "vip", Uids: []string{"100", "200"}, GuardLevel: 2},
+		{Name: RoleAdmin, Admin: false},
+	})
+	fmt.Println(config.Role.Names, config.Role.Uids)
+	if len(GetRoles()) != 2 {
+		t.Fatal("built-in role should not be redefined")
+	}
+	user := &liveclient.DanmuUser{Uid: "1", Medal: liveclient.UserMedal{Name: "other", Level: 20}}
+	if HasRole(user, "fans") {
+		t.Fatal("medal name does not match")
+	}
+	user.Medal.Name = "卡西米尔"
+	if !HasRole(user, "fans") || HasRole(user, "vip") {
+		t.Fatal("medal role should match")
+	}
+	user.Privilege = 3
+	if HasRole(user, "vip") || !HasRole(user, RolePrivilege) {
+		t.Fatal("captain is lower than admiral")
+	}
+	user.Uid = "200"
+	if !HasRole(user, "vip") {
+		t.Fatal("uid in whitelist should match")
+	}
+	fmt.Println(UserRoles(user))
+	if HasAnyRole(user, []string{RoleAdmin, "unknown"}) {
+		t.Fatal("user is not admin")
+	}
+	GetRoles()[1].Uids = nil
+	if !HasRole(user, "vip") {
+		t.Fatal("roles should only be changed by SetRoles")
+	}
+}
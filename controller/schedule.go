@@ -0,0 +1,287 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/event"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ScheduleAction string
+
+const (
+	// ScheduleActionPlaylist value is the name of playlist which becomes the only system playlist
+	ScheduleActionPlaylist ScheduleAction = "playlist"
+	// ScheduleActionVolume value is volume from 0 to 100
+	ScheduleActionVolume ScheduleAction = "volume"
+	// ScheduleActionRandom value is true or false, change random mode of system playlist
+	ScheduleActionRandom ScheduleAction = "random"
+	// ScheduleActionDiange value is roles allowed to request separated by comma, or none.
+	// it is handled by diange plugin.
+	ScheduleActionDiange ScheduleAction = "diange"
+)
+
+var ScheduleActions = []ScheduleAction{
+	ScheduleActionPlaylist, ScheduleActionVolume, ScheduleActionRandom, ScheduleActionDiange,
+}
+
+const EventScheduleTrigger event.EventId = "controller.schedule.trigger"
+
+type ScheduleTriggerEvent struct {
+	Rule *ScheduleRule
+}
+
+const scheduleCheckInterval = 15 * time.Second
+
+const ScheduleEveryDay = "1234567"
+
+var ErrorInvalidSchedule = errors.New("invalid schedule rule")
+
+type ScheduleRule struct {
+	Days   string
+	Time   string
+	Action ScheduleAction
+	Value  string
+}
+
+func (r *ScheduleRule) String() string {
+	return fmt.Sprintf("%s@%s %s=%s", r.Days, r.Time, r.Action, r.Value)
+}
+
+func (r *ScheduleRule) clock() (hour, minute int, err error) {
+	_, err = fmt.Sscanf(r.Time, "%d:%d", &hour, &minute)
+	if err != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, ErrorInvalidSchedule
+	}
+	return hour, minute, nil
+}
+
+// Validate check the rule format, it does not check whether the value is available.
+func (r *ScheduleRule) Validate() error {
+	if _, _, err := r.clock(); err != nil {
+		return err
+	}
+	if r.Days == "" {
+		return ErrorInvalidSchedule
+	}
+	for _, c := range r.Days {
+		if c < '1' || c > '7' {
+			return ErrorInvalidSchedule
+		}
+	}
+	if strings.TrimSpace(r.Value) == "" {
+		return ErrorInvalidSchedule
+	}
+	for _, a := range ScheduleActions {
+		if a == r.Action {
+			return nil
+		}
+	}
+	return ErrorInvalidSchedule
+}
+
+// onDay return true if rule is active on the weekday
+func (r *ScheduleRule) onDay(day time.Weekday) bool {
+	// 1 is Monday and 7 is Sunday
+	d := int(day)
+	if d == 0 {
+		d = 7
+	}
+	return strings.ContainsRune(r.Days, rune('0'+d))
+}
+
+// Next return the first time the rule triggers after t, zero time if the rule is invalid.
+func (r *ScheduleRule) Next(t time.Time) time.Time {
+	hour, minute, err := r.clock()
+	if err != nil {
+		return time.Time{}
+	}
+	for i := 0; i <= 7; i++ {
+		day := t.AddDate(0, 0, i)
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, t.Location())
+		if at.After(t) && r.onDay(at.Weekday()) {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+// Prev return the latest time the rule triggered at or before t, zero time if the rule is invalid.
+func (r *ScheduleRule) Prev(t time.Time) time.Time {
+	hour, minute, err := r.clock()
+	if err != nil {
+		return time.Time{}
+	}
+	for i := 0; i <= 7; i++ {
+		day := t.AddDate(0, 0, -i)
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, t.Location())
+		if !at.After(t) && r.onDay(at.Weekday()) {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+type ScheduleTransition struct {
+	Time time.Time
+	Rule *ScheduleRule
+}
+
+var scheduleLock sync.Mutex
+var scheduleLastCheck time.Time
+
+// GetScheduleRules return the rules in config, invalid rules are ignored.
+// missing fields are treated as empty because empty values at the end of list are dropped by config file.
+func GetScheduleRules() []*ScheduleRule {
+	c := config.Schedule
+	rules := make([]*ScheduleRule, 0, len(c.Days))
+	for i, days := range c.Days {
+		rule := &ScheduleRule{Days: days}
+		if i < len(c.Times) {
+			rule.Time = c.Times[i]
+		}
+		if i < len(c.Actions) {
+			rule.Action = ScheduleAction(c.Actions[i])
+		}
+		if i < len(c.Values) {
+			rule.Value = c.Values[i]
+		}
+		if err := rule.Validate(); err != nil {
+			l().Warnf("ignore invalid schedule rule %s", rule)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// SetScheduleRules replace rules in config
+func SetScheduleRules(rules []*ScheduleRule) {
+	scheduleLock.Lock()
+	defer scheduleLock.Unlock()
+	c := config.Schedule
+	c.Days = make([]string, len(rules))
+	c.Times = make([]string, len(rules))
+	c.Actions = make([]string, len(rules))
+	c.Values = make([]string, len(rules))
+	for i, r := range rules {
+		c.Days[i], c.Times[i], c.Actions[i], c.Values[i] = r.Days, r.Time, string(r.Action), r.Value
+	}
+}
+
+// UpcomingTransitions return next n transitions after t
+func UpcomingTransitions(t time.Time, n int) []ScheduleTransition {
+	transitions := make([]ScheduleTransition, 0)
+	for _, r := range GetScheduleRules() {
+		at := t
+		for i := 0; i < n; i++ {
+			at = r.Next(at)
+			if at.IsZero() {
+				break
+			}
+			transitions = append(transitions, ScheduleTransition{Time: at, Rule: r})
+		}
+	}
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].Time.Before(transitions[j].Time)
+	})
+	if len(transitions) > n {
+		transitions = transitions[:n]
+	}
+	return transitions
+}
+
+// ApplyScheduleRule apply the change of rule, rules not handled by controller
+// are passed to plugins by EventScheduleTrigger.
+func ApplyScheduleRule(rule *ScheduleRule) {
+	l().Infof("apply schedule rule %s", rule)
+	Audit(ActorSystem, AuditConfig, "schedule %s", rule)
+	switch rule.Action {
+	case ScheduleActionPlaylist:
+		applySchedulePlaylist(rule.Value)
+	case ScheduleActionVolume:
+		volume, err := strconv.ParseFloat(rule.Value, 64)
+		if err != nil {
+			l().Warnf("invalid volume %s in schedule", rule.Value)
+			break
+		}
+		SetVolume(volume)
+	case ScheduleActionRandom:
+		random, err := strconv.ParseBool(rule.Value)
+		if err != nil {
+			l().Warnf("invalid random mode %s in schedule", rule.Value)
+			break
+		}
+		SystemPlaylist.Config.RandomNext = random
+		config.Player.PlaylistRandom = random
+	}
+	EventHandler.CallA(EventScheduleTrigger, ScheduleTriggerEvent{Rule: rule})
+}
+
+func applySchedulePlaylist(name string) {
+	for i, p := range PlaylistManager {
+		if p.Name == name {
+			SetSystemPlaylist(i)
+			return
+		}
+	}
+	l().Warnf("playlist %s in schedule not found", name)
+}
+
+// checkSchedule apply rules triggered in (last, now]
+func checkSchedule(last, now time.Time) {
+	for _, t := range UpcomingTransitions(last, len(GetScheduleRules())*7) {
+		if t.Time.After(now) {
+			break
+		}
+		ApplyScheduleRule(t.Rule)
+	}
+}
+
+// applyCurrentSchedule apply the latest rule of every action,
+// so the state is same as the program has been running all the time.
+func applyCurrentSchedule(now time.Time) {
+	latest := make(map[ScheduleAction]ScheduleTransition)
+	for _, r := range GetScheduleRules() {
+		at := r.Prev(now)
+		if at.IsZero() {
+			continue
+		}
+		if t, ok := latest[r.Action]; !ok || at.After(t.Time) {
+			latest[r.Action] = ScheduleTransition{Time: at, Rule: r}
+		}
+	}
+	for _, a := range ScheduleActions {
+		if t, ok := latest[a]; ok {
+			ApplyScheduleRule(t.Rule)
+		}
+	}
+}
+
+// StartScheduler start checking schedule rules in background,
+// it should be called after plugins are loaded so they can handle the rules applied at startup.
+func StartScheduler() {
+	go runScheduler()
+}
+
+func runScheduler() {
+	scheduleLastCheck = time.Now()
+	if config.Schedule.Enable {
+		applyCurrentSchedule(scheduleLastCheck)
+	}
+	for now := range time.Tick(scheduleCheckInterval) {
+		scheduleLock.Lock()
+		last := scheduleLastCheck
+		scheduleLastCheck = now
+		scheduleLock.Unlock()
+		if !config.Schedule.Enable {
+			continue
+		}
+		checkSchedule(last, now)
+	}
+}
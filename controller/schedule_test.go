@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestScheduleRule_Next(t *testing.T) {
+	// 2022-05-02 is Monday
+	now := time.Date(2022, 5, 2, 12, 0, 0, 0, time.Local)
+	rule := &ScheduleRule{Days: "67", Time: "00:30", Action: ScheduleActionVolume, Value: "50"}
+	fmt.Println(rule.Next(now), rule.Prev(now))
+	if !rule.Next(now).Equal(time.Date(2022, 5, 7, 0, 30, 0, 0, time.Local)) {
+		t.Fatal("next should be saturday")
+	}
+	if !rule.Prev(now).Equal(time.Date(2022, 5, 1, 0, 30, 0, 0, time.Local)) {
+		t.Fatal("prev should be sunday")
+	}
+	daily := &ScheduleRule{Days: ScheduleEveryDay, Time: "12:00", Action: ScheduleActionRandom, Value: "true"}
+	if !daily.Prev(now).Equal(now) || !daily.Next(now).Equal(now.AddDate(0, 0, 1)) {
+		t.Fatal("daily rule should trigger at exact time")
+	}
+	if (&ScheduleRule{Days: "8", Time: "25:00", Action: "x"}).Validate() == nil {
+		t.Fatal("invalid rule should not pass validation")
+	}
+}
+
+func TestGetScheduleRules_MissingValue(t *testing.T) {
+	// trailing empty value is dropped when config is loaded
+	config.Schedule.Days = []string{"12345", ScheduleEveryDay}
+	config.Schedule.Times = []string{"08:00", "20:00"}
+	config.Schedule.Actions = []string{string(ScheduleActionVolume), string(ScheduleActionPlaylist)}
+	config.Schedule.Values = []string{"50"}
+	defer SetScheduleRules([]*ScheduleRule{})
+	rules := GetScheduleRules()
+	if len(rules) != 1 || rules[0].Value != "50" {
+		t.Fatal("only the rule with missing value should be ignored")
+	}
+	if (&ScheduleRule{Days: ScheduleEveryDay, Time: "20:00", Action: ScheduleActionPlaylist, Value: " "}).Validate() == nil {
+		t.Fatal("rule with empty value should not pass validation")
+	}
+}
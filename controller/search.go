@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
+	"AynaLivePlayer/util"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type searchResult struct {
+	media *player.Media
+	score float64
+}
+
+// searchRelevance return how relevant the media is to the keyword
+func searchRelevance(keyword string, media *player.Media) float64 {
+	kw := strings.ToLower(strings.TrimSpace(keyword))
+	title := strings.ToLower(media.Title)
+	relevance := util.StringSimilarity(kw, title)
+	if r := util.StringSimilarity(kw, title+" "+strings.ToLower(media.Artist)); r > relevance {
+		relevance = r
+	}
+	if kw != "" && strings.Contains(title, kw) && relevance < 0.9 {
+		relevance = 0.9
+	}
+	return relevance
+}
+
+// searchScore rank media by relevance, provider priority and its rank in provider's own result
+func searchScore(keyword string, media *player.Media, priority int, providerCount int, rank int) float64 {
+	prio := 0.0
+	if providerCount > 0 {
+		prio = float64(providerCount-priority) / float64(providerCount)
+	}
+	return searchRelevance(keyword, media)*0.6 + prio*0.2 + 0.2/float64(1+rank)
+}
+
+func searchDedupKey(media *player.Media) string {
+	return strings.ToLower(strings.Join(strings.Fields(media.Title), "")) + "|" +
+		strings.ToLower(strings.Join(strings.Fields(media.Artist), ""))
+}
+
+// mergeSearchResults remove duplicate medias across providers and sort result by score
+func mergeSearchResults(results []searchResult) []*player.Media {
+	best := make(map[string]int)
+	merged := make([]searchResult, 0, len(results))
+	for _, r := range results {
+		key := searchDedupKey(r.media)
+		if i, ok := best[key]; ok {
+			if r.score > merged[i].score {
+				merged[i] = r
+			}
+			continue
+		}
+		best[key] = len(merged)
+		merged = append(merged, r)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].score > merged[j].score
+	})
+	medias := make([]*player.Media, len(merged))
+	for i, r := range merged {
+		medias[i] = r.media
+	}
+	return medias
+}
+
+// SearchStream search keyword using providers concurrently. onUpdate (if not nil) is called
+// with merged and ranked result every time a provider returns, so slow providers won't block
+// results from other providers. providers order is used as priority.
+func SearchStream(keyword string, providers []string, onUpdate func(medias []*player.Media)) ([]*player.Media, error) {
+	l().Infof("Search for %s using %s", keyword, providers)
+	timeout := time.Duration(config.Provider.SearchTimeout) * time.Second
+	results := make([]searchResult, 0)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	succeed := 0
+	for index, pname := range providers {
+		pr, ok := provider.Providers[pname]
+		if !ok {
+			l().Warnf("Provider %s not exist", pname)
+			continue
+		}
+		wg.Add(1)
+		go func(index int, pname string, pr provider.MediaProvider) {
+			defer wg.Done()
+			ch := make(chan []*player.Media, 1)
+			go func() {
+				r, err := pr.Search(keyword)
+				if err != nil {
+					l().Warnf("Provider %s return err %s", pname, err)
+					r = nil
+				}
+				ch <- r
+			}()
+			var medias []*player.Media
+			select {
+			case medias = <-ch:
+			case <-time.After(timeout):
+				l().Warnf("Provider %s search timeout", pname)
+				return
+			}
+			if medias == nil {
+				return
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			succeed++
+			for rank, m := range medias {
+				results = append(results, searchResult{
+					media: m,
+					score: searchScore(keyword, m, index, len(providers), rank),
+				})
+			}
+			if onUpdate != nil {
+				onUpdate(mergeSearchResults(results))
+			}
+		}(index, pname, pr)
+	}
+	wg.Wait()
+	if succeed == 0 {
+		return nil, provider.ErrorExternalApi
+	}
+	return mergeSearchResults(results), nil
+}
+
+func Search(keyword string) ([]*player.Media, error) {
+	return SearchStream(keyword, config.Provider.Priority, nil)
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"AynaLivePlayer/player"
+	"fmt"
+	"testing"
+)
+
+func TestMergeSearchResults(t *testing.T) {
+	keyword := "双截棍 周杰伦"
+	results := []searchResult{
+		{media: &player.Media{Title: "双截棍", Artist: "周杰伦"}},
+		{media: &player.Media{Title: "双截棍 (Live)", Artist: "周杰伦"}},
+		{media: &player.Media{Title: "双截棍", Artist: "周杰伦"}},
+		{media: &player.Media{Title: "龙拳", Artist: "周杰伦"}},
+	}
+	for i := range results {
+		results[i].score = searchScore(keyword, results[i].media, i%2, 2, i)
+	}
+	medias := mergeSearchResults(results)
+	for _, m := range medias {
+		fmt.Println(m.Title, m.Artist)
+	}
+	if len(medias) != 3 {
+		t.Fatalf("duplicate media should be removed, got %d", len(medias))
+	}
+	if medias[0].Title != "双截棍" {
+		t.Fatalf("most relevant media should be first, got %s", medias[0].Title)
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/player"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const EventSelectionUpdate event.EventId = "controller.selection.update"
+
+// SelectionUpdateEvent contains all selections waiting for user's choice
+type SelectionUpdateEvent struct {
+	Selections []*Selection
+}
+
+var ErrorNoSelection = errors.New("no selection or selection expired")
+
+// Selection is search candidates of a request, waiting for the requester to choose one
+type Selection struct {
+	User       *liveclient.DanmuUser
+	Keyword    string
+	Candidates []*player.Media
+	Expire     time.Time
+}
+
+var selections = make(map[string]*Selection)
+var selectionLock sync.Mutex
+
+// StartSelection search keyword and keep at most n candidates for the user to choose in timeout,
+// previous selection of the user is replaced.
+func StartSelection(keyword string, pname string, user *liveclient.DanmuUser, n int, timeout time.Duration) (*Selection, error) {
+	if err := checkRequester(user); err != nil {
+		return nil, err
+	}
+	medias, err := SearchRequest(keyword, pname)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 && len(medias) > n {
+		medias = medias[:n]
+	}
+	s := &Selection{User: user, Keyword: keyword, Candidates: medias, Expire: time.Now().Add(timeout)}
+	l().Infof("start selection of %s for %s(%s) with %d candidates", keyword, user.Username, user.Uid, len(medias))
+	selectionLock.Lock()
+	selections[user.Uid] = s
+	selectionLock.Unlock()
+	notifySelectionUpdate()
+	return s, nil
+}
+
+// TakeSelection return candidate at index chosen by the user and remove the selection,
+// the candidate has passed CheckRequest.
+func TakeSelection(user *liveclient.DanmuUser, index int) (*player.Media, error) {
+	selectionLock.Lock()
+	s, ok := selections[user.Uid]
+	if !ok || s.Expire.Before(time.Now()) {
+		selectionLock.Unlock()
+		return nil, ErrorNoSelection
+	}
+	if index < 0 || index >= len(s.Candidates) {
+		selectionLock.Unlock()
+		return nil, ErrorCommandArgs
+	}
+	delete(selections, user.Uid)
+	selectionLock.Unlock()
+	notifySelectionUpdate()
+	media := s.Candidates[index]
+	if err := CheckRequest(media, user); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// ExpireSelections remove expired selections, return number of removed selections.
+func ExpireSelections() int {
+	selectionLock.Lock()
+	now := time.Now()
+	cnt := 0
+	for uid, s := range selections {
+		if s.Expire.Before(now) {
+			delete(selections, uid)
+			cnt++
+		}
+	}
+	selectionLock.Unlock()
+	if cnt > 0 {
+		notifySelectionUpdate()
+	}
+	return cnt
+}
+
+// Selections return active selections ordered by expire time
+func Selections() []*Selection {
+	selectionLock.Lock()
+	defer selectionLock.Unlock()
+	result := make([]*Selection, 0, len(selections))
+	for _, s := range selections {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Expire.Before(result[j].Expire)
+	})
+	return result
+}
+
+func notifySelectionUpdate() {
+	EventHandler.CallA(EventSelectionUpdate, SelectionUpdateEvent{Selections: Selections()})
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/util"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SetlistPath keep the setlist of the latest session, so restarting the program won't lose it
+const SetlistPath = "./setlist.json"
+
+const EventSetlistUpdate event.EventId = "controller.setlist.update"
+
+type SetlistUpdateEvent struct {
+	Setlist *Setlist
+}
+
+type SetlistEntry struct {
+	// Offset is seconds from session start
+	Offset    int
+	Time      int64
+	Title     string
+	Artist    string
+	Requester string
+}
+
+// Setlist record songs played in one stream session
+type Setlist struct {
+	// Start is the unix timestamp of stream start
+	Start int64
+	// End is zero while the session is running
+	End     int64
+	Manual  bool
+	Entries []*SetlistEntry
+	lock    sync.RWMutex
+}
+
+var CurrentSetlist *Setlist
+
+func loadSetlist() *Setlist {
+	s := &Setlist{Entries: make([]*SetlistEntry, 0)}
+	if err := util.LoadJson(SetlistPath, s); err != nil {
+		l().Infof("load setlist from %s failed: %s", SetlistPath, err)
+	}
+	return s
+}
+
+func (s *Setlist) save() {
+	if err := util.SaveJson(SetlistPath, s); err != nil {
+		l().Warnf("save setlist to %s failed: %s", SetlistPath, err)
+	}
+}
+
+// Running return true if the session has started and not ended yet
+func (s *Setlist) Running() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.Start > 0 && s.End == 0
+}
+
+func (s *Setlist) Size() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return len(s.Entries)
+}
+
+func (s *Setlist) Get(index int) *SetlistEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if index < 0 || index >= len(s.Entries) {
+		return nil
+	}
+	return s.Entries[index]
+}
+
+func (s *Setlist) add(media *player.Media, now time.Time) {
+	s.lock.Lock()
+	if s.Start == 0 || s.End != 0 {
+		s.lock.Unlock()
+		return
+	}
+	s.Entries = append(s.Entries, &SetlistEntry{
+		Offset:    int(now.Unix() - s.Start),
+		Time:      now.Unix(),
+		Title:     media.Title,
+		Artist:    media.Artist,
+		Requester: media.ToUser().Name,
+	})
+	s.save()
+	s.lock.Unlock()
+	EventHandler.CallA(EventSetlistUpdate, SetlistUpdateEvent{Setlist: s})
+}
+
+// StartSession start a new setlist, manual means it is started by the streamer instead of live status.
+// the current media is added as the first entry if it is playing.
+func StartSession(start time.Time, manual bool) {
+	l().Infof("start setlist session at %s, manual=%t", start.Format("2006-01-02 15:04:05"), manual)
+	CurrentSetlist.lock.Lock()
+	CurrentSetlist.Start = start.Unix()
+	CurrentSetlist.End = 0
+	CurrentSetlist.Manual = manual
+	CurrentSetlist.Entries = make([]*SetlistEntry, 0)
+	CurrentSetlist.save()
+	CurrentSetlist.lock.Unlock()
+	if CurrentMedia != nil && !MainPlayer.IsIdle() {
+		CurrentSetlist.add(CurrentMedia, time.Now())
+		return
+	}
+	EventHandler.CallA(EventSetlistUpdate, SetlistUpdateEvent{Setlist: CurrentSetlist})
+}
+
+func EndSession() {
+	if !CurrentSetlist.Running() {
+		return
+	}
+	l().Info("end setlist session")
+	CurrentSetlist.lock.Lock()
+	CurrentSetlist.End = time.Now().Unix()
+	CurrentSetlist.save()
+	CurrentSetlist.lock.Unlock()
+	EventHandler.CallA(EventSetlistUpdate, SetlistUpdateEvent{Setlist: CurrentSetlist})
+}
+
+func formatOffset(offset int) string {
+	if offset < 0 {
+		offset = 0
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", offset/3600, offset/60%60, offset%60)
+}
+
+func (e *SetlistEntry) Name() string {
+	if e.Artist == "" {
+		return e.Title
+	}
+	return e.Title + " - " + e.Artist
+}
+
+// ExportChapters write the setlist as chapters text which can be pasted into VOD description,
+// one "hh:mm:ss title - artist" per line.
+func (s *Setlist) ExportChapters(w io.Writer) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var sb strings.Builder
+	for _, e := range s.Entries {
+		sb.WriteString(formatOffset(e.Offset))
+		sb.WriteString(" ")
+		sb.WriteString(e.Name())
+		sb.WriteString("\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (s *Setlist) ExportCSV(w io.Writer) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"Offset", "Time", "Title", "Artist", "Requester"})
+	for _, e := range s.Entries {
+		_ = writer.Write([]string{
+			formatOffset(e.Offset),
+			time.Unix(e.Time, 0).Format("2006-01-02 15:04:05"),
+			e.Title, e.Artist, e.Requester,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *Setlist) ExportJSON(w io.Writer) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	content, err := util.MarshalIndentUnescape(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, content)
+	return err
+}
+
+func handleSetlistPlay(event *event.Event) {
+	CurrentSetlist.add(event.Data.(player.PlayEvent).Media, time.Now())
+}
+
+func handleSetlistLiveStatus(event *event.Event) {
+	data := event.Data.(liveclient.LiveStatusEvent)
+	if !data.Live {
+		if !CurrentSetlist.Manual {
+			EndSession()
+		}
+		return
+	}
+	// live status might be sent multiple times in one stream
+	if CurrentSetlist.Running() && (CurrentSetlist.Manual || data.StartTime.Unix()-CurrentSetlist.Start < StreamGap) {
+		l().Info("setlist session is already running, ignore live status")
+		return
+	}
+	StartSession(data.StartTime, false)
+}
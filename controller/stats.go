@@ -0,0 +1,300 @@
+package controller
+
+import (
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
+	"bufio"
+	"encoding/json"
+	"github.com/aynakeya/go-mpv"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsPath is the file of play records, one json record per line
+const StatsPath = "./play_stats.jsonl"
+
+// StreamGap is the minimum idle time between two streams
+const StreamGap = 3600
+
+// PlayRecord is one play of a media
+type PlayRecord struct {
+	// Time is the unix timestamp when the media started playing
+	Time     int64
+	Title    string
+	Artist   string
+	Album    string
+	Cover    string
+	Provider string
+	Id       string
+	// Requester is the name of the user who added the media, RequesterUid is empty if it is not a viewer
+	Requester    string
+	RequesterUid string
+	Skipped      bool
+	// PlayTime is how long the media has been played, in seconds
+	PlayTime int
+	Duration int
+}
+
+// SongKey identify a song across records
+func (r *PlayRecord) SongKey() string {
+	if r.Provider != "" && r.Id != "" {
+		return r.Provider + ":" + r.Id
+	}
+	return strings.ToLower(r.Title + "|" + r.Artist)
+}
+
+type StatEntry struct {
+	Key      string
+	Name     string
+	Count    int
+	PlayTime int
+}
+
+type StreamSummary struct {
+	Start      int64
+	End        int64
+	Plays      int
+	Skipped    int
+	Requested  int
+	Requesters int
+	PlayTime   int
+}
+
+type StatsStore struct {
+	Records  []*PlayRecord
+	Handler  *event.Handler
+	filename string
+	current  *PlayRecord
+	position float64
+	lock     sync.RWMutex
+}
+
+var Stats *StatsStore
+
+func NewStatsStore(filename string) *StatsStore {
+	s := &StatsStore{
+		Records:  make([]*PlayRecord, 0),
+		Handler:  event.NewHandler(),
+		filename: filename,
+	}
+	if err := s.load(); err != nil {
+		l().Infof("load play stats from %s failed: %s", filename, err)
+	}
+	return s
+}
+
+func (s *StatsStore) load() error {
+	f, err := os.Open(s.filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r PlayRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			l().Warnf("skip invalid play record: %s", err)
+			continue
+		}
+		s.Records = append(s.Records, &r)
+	}
+	return scanner.Err()
+}
+
+func (s *StatsStore) append(r *PlayRecord) {
+	f, err := os.OpenFile(s.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		l().Warnf("open play stats %s failed: %s", s.filename, err)
+		return
+	}
+	defer f.Close()
+	data, _ := json.Marshal(r)
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		l().Warnf("write play stats %s failed: %s", s.filename, err)
+	}
+}
+
+func newPlayRecord(media *player.Media) *PlayRecord {
+	r := &PlayRecord{
+		Time:     time.Now().Unix(),
+		Title:    media.Title,
+		Artist:   media.Artist,
+		Album:    media.Album,
+		Cover:    media.Cover.Url,
+		Duration: media.Duration,
+	}
+	if meta, ok := media.Meta.(provider.Meta); ok {
+		r.Provider = meta.Name
+		r.Id = meta.Id
+	}
+	if u := media.DanmuUser(); u != nil {
+		r.Requester = u.Username
+		r.RequesterUid = u.Uid
+	}
+	if u := media.SystemUser(); u != nil {
+		r.Requester = u.Name
+	}
+	return r
+}
+
+// Start finish the playing record as skipped, and start recording the new media
+func (s *StatsStore) Start(media *player.Media) {
+	s.Finish(true)
+	s.lock.Lock()
+	r := newPlayRecord(media)
+	s.current = r
+	s.position = 0
+	s.lock.Unlock()
+	s.Handler.CallA(EventHistoryUpdate, HistoryUpdateEvent{Record: r})
+}
+
+// Finish write the playing record to disk, skipped is false if the media reached its end.
+func (s *StatsStore) Finish(skipped bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.current == nil {
+		return
+	}
+	r := s.current
+	s.current = nil
+	r.PlayTime = int(s.position)
+	r.Skipped = skipped
+	l().Debugf("record play of %s, played %ds, skipped=%t", r.Title, r.PlayTime, r.Skipped)
+	s.Records = append(s.Records, r)
+	s.append(r)
+}
+
+// UpdatePosition record the furthest position of current media
+func (s *StatsStore) UpdatePosition(pos float64) {
+	s.lock.Lock()
+	if pos > s.position {
+		s.position = pos
+	}
+	s.lock.Unlock()
+}
+
+// since returns records started at or after since, 0 means all records.
+func (s *StatsStore) since(since int64) []*PlayRecord {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	index := sort.Search(len(s.Records), func(i int) bool {
+		return s.Records[i].Time >= since
+	})
+	return s.Records[index:]
+}
+
+func topEntries(records []*PlayRecord, n int, key func(r *PlayRecord) (string, string)) []StatEntry {
+	entries := make(map[string]*StatEntry)
+	for _, r := range records {
+		k, name := key(r)
+		if k == "" {
+			continue
+		}
+		e, ok := entries[k]
+		if !ok {
+			e = &StatEntry{Key: k, Name: name}
+			entries[k] = e
+		}
+		e.Count++
+		e.PlayTime += r.PlayTime
+	}
+	result := make([]StatEntry, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, *e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// TopSongs return n most played songs since the timestamp, n <= 0 means no limit
+func (s *StatsStore) TopSongs(n int, since int64) []StatEntry {
+	return topEntries(s.since(since), n, func(r *PlayRecord) (string, string) {
+		if r.Artist == "" {
+			return r.SongKey(), r.Title
+		}
+		return r.SongKey(), r.Title + " - " + r.Artist
+	})
+}
+
+// TopArtists return n most played artists since the timestamp, songs with multiple artists count for each of them
+func (s *StatsStore) TopArtists(n int, since int64) []StatEntry {
+	records := make([]*PlayRecord, 0)
+	for _, r := range s.since(since) {
+		for _, artist := range strings.Split(r.Artist, ",") {
+			artist = strings.TrimSpace(artist)
+			if artist == "" {
+				continue
+			}
+			rc := *r
+			rc.Artist = artist
+			records = append(records, &rc)
+		}
+	}
+	return topEntries(records, n, func(r *PlayRecord) (string, string) {
+		return strings.ToLower(r.Artist), r.Artist
+	})
+}
+
+// TopRequesters return n viewers who requested most songs since the timestamp
+func (s *StatsStore) TopRequesters(n int, since int64) []StatEntry {
+	return topEntries(s.since(since), n, func(r *PlayRecord) (string, string) {
+		return r.RequesterUid, r.Requester
+	})
+}
+
+// StreamSummaries group records into streams split by StreamGap, latest stream first
+func (s *StatsStore) StreamSummaries(since int64) []StreamSummary {
+	summaries := make([]StreamSummary, 0)
+	var current *StreamSummary
+	var requesters map[string]bool
+	for _, r := range s.since(since) {
+		if current == nil || r.Time-current.End > StreamGap {
+			if current != nil {
+				summaries = append(summaries, *current)
+			}
+			current = &StreamSummary{Start: r.Time}
+			requesters = make(map[string]bool)
+		}
+		current.End = r.Time + int64(r.PlayTime)
+		current.Plays++
+		current.PlayTime += r.PlayTime
+		if r.Skipped {
+			current.Skipped++
+		}
+		if r.RequesterUid != "" {
+			current.Requested++
+			requesters[r.RequesterUid] = true
+			current.Requesters = len(requesters)
+		}
+	}
+	if current != nil {
+		summaries = append(summaries, *current)
+	}
+	for i, j := 0, len(summaries)-1; i < j; i, j = i+1, j-1 {
+		summaries[i], summaries[j] = summaries[j], summaries[i]
+	}
+	return summaries
+}
+
+func handleStatsPlay(event *event.Event) {
+	Stats.Start(event.Data.(player.PlayEvent).Media)
+}
+
+func handleStatsPosition(property *mpv.EventProperty) {
+	if property.Data == nil {
+		return
+	}
+	Stats.UpdatePosition(property.Data.(mpv.Node).Value.(float64))
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatsStore_Query(t *testing.T) {
+	filename := filepath.Join(os.TempDir(), "play_stats_test.jsonl")
+	defer os.Remove(filename)
+	s := NewStatsStore(filename)
+	user := &liveclient.DanmuUser{Uid: "10086", Username: "aynakeya"}
+	s.Start(&player.Media{Title: "染", Artist: "reol", Meta: provider.Meta{Name: "netease", Id: "123"}, User: user})
+	s.UpdatePosition(120)
+	s.Start(&player.Media{Title: "龙拳", Artist: "周杰伦,reol"})
+	s.UpdatePosition(30)
+	s.Start(&player.Media{Title: "染", Artist: "reol", Meta: provider.Meta{Name: "netease", Id: "123"}})
+	s.Finish(false)
+	fmt.Println(s.TopSongs(10, 0))
+	fmt.Println(s.TopArtists(10, 0))
+	fmt.Println(s.TopRequesters(10, 0))
+	fmt.Println(s.StreamSummaries(0))
+	if songs := s.TopSongs(1, 0); len(songs) != 1 || songs[0].Count != 2 {
+		t.Fatal("top song should be played twice")
+	}
+	if artists := s.TopArtists(1, 0); artists[0].Name != "reol" || artists[0].Count != 3 {
+		t.Fatal("top artist should be reol")
+	}
+	if len(s.TopRequesters(10, 0)) != 1 {
+		t.Fatal("only one song is requested by viewer")
+	}
+	if NewStatsStore(filename).StreamSummaries(0)[0].Skipped != 2 {
+		t.Fatal("stats should be persisted")
+	}
+}
+
+func TestStatsStore_History(t *testing.T) {
+	filename := filepath.Join(os.TempDir(), "play_history_test.jsonl")
+	defer os.Remove(filename)
+	s := NewStatsStore(filename)
+	user := &liveclient.DanmuUser{Uid: "10086", Username: "aynakeya"}
+	s.Start(&player.Media{Title: "染", Artist: "reol", Meta: provider.Meta{Name: "netease", Id: "123"}, User: user})
+	s.Start(&player.Media{Title: "龙拳", Artist: "周杰伦", Meta: provider.Meta{Name: "kuwo", Id: "456"}, User: &player.User{Name: "System"}})
+	records := s.Query(HistoryFilter{})
+	if len(records) != 2 || records[0].Title != "龙拳" {
+		t.Fatal("playing media should be the latest record")
+	}
+	if records[0].Requester != "System" || records[0].RequesterUid != "" {
+		t.Fatal("requester of non-viewer should be recorded by name only")
+	}
+	if r := s.Query(HistoryFilter{Requester: "10086"}); len(r) != 1 || r[0].Title != "染" {
+		t.Fatal("filter by requester uid failed")
+	}
+	if r := s.Query(HistoryFilter{Provider: "kuwo"}); len(r) != 1 {
+		t.Fatal("filter by provider failed")
+	}
+	if len(s.Providers()) != 2 {
+		t.Fatal("providers should be netease and kuwo")
+	}
+}
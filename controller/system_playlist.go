@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/util"
+)
+
+// normalizePlaylistWeights make sure every playlist in PlaylistManager has a weight.
+// old config without weights uses PlaylistIndex as the only idle playlist.
+func normalizePlaylistWeights() {
+	if len(config.Player.PlaylistsWeight) == len(PlaylistManager) {
+		return
+	}
+	weights := make([]int, len(PlaylistManager))
+	if len(config.Player.PlaylistsWeight) == 0 {
+		l().Infof("no playlist weights, use playlist.index=%d as idle playlist", config.Player.PlaylistIndex)
+	} else {
+		l().Warn("playlist weights does not match playlists, reset weights")
+		copy(weights, config.Player.PlaylistsWeight)
+	}
+	config.Player.PlaylistsWeight = weights
+	for _, w := range weights {
+		if w > 0 {
+			return
+		}
+	}
+	if config.Player.PlaylistIndex >= 0 && config.Player.PlaylistIndex < len(weights) {
+		config.Player.PlaylistsWeight[config.Player.PlaylistIndex] = 1
+	}
+}
+
+func GetSystemPlaylistWeight(index int) int {
+	if index < 0 || index >= len(config.Player.PlaylistsWeight) {
+		return 0
+	}
+	return config.Player.PlaylistsWeight[index]
+}
+
+// SetSystemPlaylistWeight set the weight of playlist in idle rotation,
+// weight <= 0 remove the playlist from idle rotation.
+func SetSystemPlaylistWeight(index int, weight int) {
+	l().Infof("try set system playlist weight playlist.index=%d weight=%d", index, weight)
+	if index < 0 || index >= len(PlaylistManager) {
+		l().Warnf("playlist.index=%d not found", index)
+		return
+	}
+	normalizePlaylistWeights()
+	if weight < 0 {
+		weight = 0
+	}
+	if weight > 0 && PlaylistManager[index].Size() == 0 {
+		if err := PreparePlaylist(PlaylistManager[index]); err != nil {
+			return
+		}
+	}
+	config.Player.PlaylistsWeight[index] = weight
+	RefreshSystemPlaylist()
+}
+
+// RefreshSystemPlaylist rebuild SystemPlaylist from all playlists with positive weight.
+func RefreshSystemPlaylist() {
+	l().Info("refresh system playlist")
+	normalizePlaylistWeights()
+	medias := make([]*player.Media, 0)
+	for i, p := range PlaylistManager {
+		if GetSystemPlaylistWeight(i) <= 0 {
+			continue
+		}
+		p.Lock.RLock()
+		ApplyUser(p.Playlist, player.PlaylistUser)
+		medias = append(medias, p.Playlist...)
+		p.Lock.RUnlock()
+	}
+	SystemPlaylist.Replace(medias)
+}
+
+// nextSystemMedia return the next system media, media already picked by prefetcher goes first.
+func nextSystemMedia() *player.Media {
+	if m := takePrefetchedSystemMedia(); m != nil {
+		return m
+	}
+	return pickSystemMedia()
+}
+
+// pickSystemMedia pick a playlist by weight, and then get next media from that playlist.
+func pickSystemMedia() *player.Media {
+	weights := make([]int, len(PlaylistManager))
+	for i, p := range PlaylistManager {
+		if p.Size() > 0 {
+			weights[i] = GetSystemPlaylistWeight(i)
+		}
+	}
+	index := util.WeightedRandomIndex(weights)
+	if index < 0 {
+		l().Info("no weighted system playlist available")
+		return nil
+	}
+	l().Debugf("pick next media from system playlist %s", PlaylistManager[index].Name)
+	PlaylistManager[index].Config.RandomNext = SystemPlaylist.Config.RandomNext
+	return PlaylistManager[index].Next()
+}
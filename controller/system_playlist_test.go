@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/player"
+	"testing"
+)
+
+func TestNormalizePlaylistWeights(t *testing.T) {
+	defer func(manager []*player.Playlist, weights []int, index int) {
+		PlaylistManager = manager
+		config.Player.PlaylistsWeight = weights
+		config.Player.PlaylistIndex = index
+	}(PlaylistManager, config.Player.PlaylistsWeight, config.Player.PlaylistIndex)
+	PlaylistManager = []*player.Playlist{
+		player.NewPlaylist("a", player.PlaylistConfig{}),
+		player.NewPlaylist("b", player.PlaylistConfig{}),
+		player.NewPlaylist("c", player.PlaylistConfig{}),
+	}
+	// old config without weights
+	config.Player.PlaylistsWeight = []int{}
+	config.Player.PlaylistIndex = 2
+	normalizePlaylistWeights()
+	if GetSystemPlaylistWeight(0) != 0 || GetSystemPlaylistWeight(1) != 0 || GetSystemPlaylistWeight(2) != 1 {
+		t.Fatalf("weights should be built from playlist index, got %v", config.Player.PlaylistsWeight)
+	}
+	config.Player.PlaylistsWeight = []int{0, 3}
+	normalizePlaylistWeights()
+	if len(config.Player.PlaylistsWeight) != 3 || GetSystemPlaylistWeight(1) != 3 || GetSystemPlaylistWeight(2) != 0 {
+		t.Fatalf("existing weights should be kept, got %v", config.Player.PlaylistsWeight)
+	}
+}
@@ -0,0 +1,72 @@
+package gui
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/i18n"
+	"AynaLivePlayer/player"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+var Approval = &PlaylistContainer{}
+
+func createApprovalList() fyne.CanvasObject {
+	Approval.Playlist = controller.PendingPlaylist
+	Approval.List = widget.NewList(
+		func() int {
+			return Approval.Playlist.Size()
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil,
+				widget.NewLabel("index"),
+				container.NewHBox(
+					widget.NewButtonWithIcon("", theme.ConfirmIcon(), nil),
+					widget.NewButtonWithIcon("", theme.CancelIcon(), nil),
+				),
+				container.NewGridWithColumns(3,
+					newLabelWithWrapping("title", fyne.TextTruncate),
+					newLabelWithWrapping("artist", fyne.TextTruncate),
+					newLabelWithWrapping("user", fyne.TextTruncate)))
+		},
+		func(id widget.ListItemID, object fyne.CanvasObject) {
+			m := Approval.Playlist.Playlist[id]
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[0].(*widget.Label).SetText(
+				m.Title)
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[1].(*widget.Label).SetText(
+				m.Artist)
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[2].(*widget.Label).SetText(
+				m.ToUser().Name)
+			object.(*fyne.Container).Objects[1].(*widget.Label).SetText(fmt.Sprintf("%d", id+1))
+			// act on the media instead of index, which might be changed before tapping
+			btns := object.(*fyne.Container).Objects[2].(*fyne.Container).Objects
+			btns[0].(*widget.Button).OnTapped = func() {
+				controller.ApproveMedia(m, controller.ActorGUI)
+			}
+			btns[1].(*widget.Button).OnTapped = func() {
+				controller.RejectMedia(m, controller.ActorGUI)
+			}
+		})
+	registerApprovalHandler()
+	return container.NewBorder(
+		container.NewBorder(nil, nil,
+			widget.NewLabel("#"), widget.NewLabel(i18n.T("gui.approval.operation")),
+			container.NewGridWithColumns(3,
+				widget.NewLabel(i18n.T("gui.approval.title")),
+				widget.NewLabel(i18n.T("gui.approval.artist")),
+				widget.NewLabel(i18n.T("gui.approval.user")))),
+		nil, nil, nil,
+		Approval.List,
+	)
+}
+
+func registerApprovalHandler() {
+	Approval.Playlist.Handler.RegisterA(player.EventPlaylistUpdate, "gui.approval.update", func(event *event.Event) {
+		Approval.Playlist.Lock.RLock()
+		Approval.List.Refresh()
+		Approval.Playlist.Lock.RUnlock()
+	})
+}
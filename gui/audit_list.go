@@ -0,0 +1,113 @@
+package gui
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/i18n"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"strings"
+	"time"
+)
+
+type AuditContainer struct {
+	Filter  controller.AuditFilter
+	Records []*controller.AuditRecord
+	List    *widget.List
+}
+
+var AuditList = &AuditContainer{}
+
+// Reload query audit log with current filter
+func (a *AuditContainer) Reload() {
+	a.Records = controller.AuditLog.Query(a.Filter)
+	a.List.Refresh()
+}
+
+func createAuditFilter() fyne.CanvasObject {
+	all := i18n.T("gui.audit.filter.all")
+	actorTypes := []string{all}
+	for _, t := range controller.AuditActorTypes {
+		actorTypes = append(actorTypes, string(t))
+	}
+	actor := widget.NewSelect(actorTypes, nil)
+	actor.SetSelectedIndex(0)
+	actions := []string{all}
+	for _, a := range controller.AuditActions {
+		actions = append(actions, string(a))
+	}
+	action := widget.NewSelect(actions, nil)
+	action.SetSelectedIndex(0)
+	keyword := widget.NewEntry()
+	keyword.SetPlaceHolder(i18n.T("gui.audit.keyword"))
+	since := widget.NewEntry()
+	since.SetPlaceHolder(historyDateLayout)
+	until := widget.NewEntry()
+	until.SetPlaceHolder(historyDateLayout)
+	search := widget.NewButtonWithIcon(i18n.T("gui.audit.filter.search"), theme.SearchIcon(), func() {
+		AuditList.Filter = controller.AuditFilter{
+			Keyword: strings.TrimSpace(keyword.Text),
+			Since:   parseHistoryDate(since.Text),
+		}
+		if actor.SelectedIndex() > 0 {
+			AuditList.Filter.ActorType = controller.AuditActorType(actor.Selected)
+		}
+		if action.SelectedIndex() > 0 {
+			AuditList.Filter.Action = controller.AuditAction(action.Selected)
+		}
+		// until date is inclusive
+		if t := parseHistoryDate(until.Text); t > 0 {
+			AuditList.Filter.Until = t + 24*3600
+		}
+		AuditList.Reload()
+	})
+	return container.NewBorder(nil, nil, nil, search,
+		container.NewGridWithColumns(5, actor, action, keyword, since, until))
+}
+
+func createAuditList() fyne.CanvasObject {
+	AuditList.List = widget.NewList(
+		func() int {
+			return len(AuditList.Records)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil,
+				widget.NewLabel("time"), nil,
+				container.NewGridWithColumns(3,
+					newLabelWithWrapping("actor", fyne.TextTruncate),
+					newLabelWithWrapping("action", fyne.TextTruncate),
+					newLabelWithWrapping("detail", fyne.TextTruncate)))
+		},
+		func(id widget.ListItemID, object fyne.CanvasObject) {
+			if id >= len(AuditList.Records) {
+				return
+			}
+			r := AuditList.Records[id]
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[0].(*widget.Label).SetText(
+				string(r.Actor.Type) + ": " + r.Actor.String())
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[1].(*widget.Label).SetText(
+				string(r.Action))
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[2].(*widget.Label).SetText(
+				r.Detail)
+			object.(*fyne.Container).Objects[1].(*widget.Label).SetText(
+				time.Unix(r.Time, 0).Format("2006-01-02 15:04:05"))
+		})
+	AuditList.Reload()
+	controller.AuditLog.Handler.RegisterA(controller.EventAuditUpdate, "gui.audit.update", func(event *event.Event) {
+		AuditList.Reload()
+	})
+	return container.NewBorder(
+		container.NewVBox(
+			createAuditFilter(),
+			container.NewBorder(nil, nil,
+				widget.NewLabel(i18n.T("gui.audit.time")), nil,
+				container.NewGridWithColumns(3,
+					widget.NewLabel(i18n.T("gui.audit.actor")),
+					widget.NewLabel(i18n.T("gui.audit.action")),
+					widget.NewLabel(i18n.T("gui.audit.detail"))))),
+		nil, nil, nil,
+		AuditList.List,
+	)
+}
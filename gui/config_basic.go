@@ -56,6 +56,13 @@ func (b *bascicConfig) CreatePanel() fyne.CanvasObject {
 			binding.BindBool(&config.Player.SkipPlaylist),
 		),
 	)
-	b.panel = container.NewVBox(randomPlaylist, outputDevice, skipPlaylist)
+	autoplay := container.NewHBox(
+		widget.NewLabel(i18n.T("gui.config.basic.autoplay")),
+		widget.NewCheckWithData(
+			i18n.T("gui.config.basic.autoplay.prompt"),
+			binding.BindBool(&config.Player.Autoplay),
+		),
+	)
+	b.panel = container.NewVBox(randomPlaylist, outputDevice, skipPlaylist, autoplay)
 	return b.panel
 }
@@ -0,0 +1,60 @@
+package gui
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/i18n"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+type liveRoomConfig struct {
+	panel fyne.CanvasObject
+}
+
+func (c *liveRoomConfig) Title() string {
+	return i18n.T("gui.config.liveroom.title")
+}
+
+func (c *liveRoomConfig) Description() string {
+	return i18n.T("gui.config.liveroom.description")
+}
+
+func (c *liveRoomConfig) CreatePanel() fyne.CanvasObject {
+	if c.panel != nil {
+		return c.panel
+	}
+	auditor := controller.NewConfigAuditor(controller.ActorGUI, config.LiveRoom)
+	bind := NewConfigBinder(binding.NewDataListener(auditor.Changed))
+	sendReply := container.NewHBox(
+		widget.NewLabel(i18n.T("gui.config.liveroom.send_reply")),
+		widget.NewCheckWithData(
+			i18n.T("gui.config.liveroom.send_reply.prompt"),
+			bind.Bool(&config.LiveRoom.SendReply)),
+	)
+	sessData := widget.NewPasswordEntry()
+	sessData.Bind(bind.String(&config.LiveRoom.BilibiliSessData))
+	biliJct := widget.NewPasswordEntry()
+	biliJct.Bind(bind.String(&config.LiveRoom.BilibiliJct))
+	twitchToken := widget.NewPasswordEntry()
+	twitchToken.Bind(bind.String(&config.LiveRoom.TwitchToken))
+	account := container.New(layout.NewFormLayout(),
+		widget.NewLabel(i18n.T("gui.config.liveroom.uid")),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&config.LiveRoom.BilibiliUid))),
+		widget.NewLabel("SESSDATA"), sessData,
+		widget.NewLabel("bili_jct"), biliJct,
+		widget.NewLabel(i18n.T("gui.config.liveroom.twitch_username")),
+		widget.NewEntryWithData(bind.String(&config.LiveRoom.TwitchUsername)),
+		widget.NewLabel(i18n.T("gui.config.liveroom.twitch_token")), twitchToken,
+		widget.NewLabel(i18n.T("gui.config.liveroom.message_interval")),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&config.LiveRoom.MessageInterval))),
+		widget.NewLabel(i18n.T("gui.config.liveroom.message_max_length")),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&config.LiveRoom.MessageMaxLength))),
+	)
+	c.panel = container.NewVBox(sendReply, account,
+		widget.NewLabel(i18n.T("gui.config.liveroom.reconnect_prompt")))
+	return c.panel
+}
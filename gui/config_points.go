@@ -0,0 +1,72 @@
+package gui
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/i18n"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"strconv"
+)
+
+type pointsConfig struct {
+	panel fyne.CanvasObject
+}
+
+func (p *pointsConfig) Title() string {
+	return i18n.T("gui.config.points.title")
+}
+
+func (p *pointsConfig) Description() string {
+	return i18n.T("gui.config.points.description")
+}
+
+func (p *pointsConfig) CreatePanel() fyne.CanvasObject {
+	if p.panel != nil {
+		return p.panel
+	}
+	c := config.Points
+	auditor := controller.NewConfigAuditor(controller.ActorGUI, c)
+	bind := NewConfigBinder(binding.NewDataListener(auditor.Changed))
+	earn := container.New(layout.NewFormLayout(),
+		widget.NewLabel(i18n.T("gui.config.points.chat_points")),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&c.ChatPoints))),
+		widget.NewLabel(i18n.T("gui.config.points.chat_interval")),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&c.ChatInterval))),
+		widget.NewLabel(i18n.T("gui.config.points.guard_bonus")),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&c.GuardChatBonus))),
+		widget.NewLabel(i18n.T("gui.config.points.points_per_yuan")),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&c.PointsPerYuan))),
+		widget.NewLabel(i18n.T("gui.config.points.reply_balance")),
+		widget.NewEntryWithData(bind.String(&c.ReplyBalance)),
+		widget.NewLabel(i18n.T("gui.config.points.reply_not_enough")),
+		widget.NewEntryWithData(bind.String(&c.ReplyNotEnough)),
+	)
+	// commands are registered when plugins are loaded, which is before creating the panel
+	prices := make([]fyne.CanvasObject, 0)
+	for _, cmd := range controller.Commands {
+		name := cmd.Name
+		if name == "" {
+			continue
+		}
+		entry := widget.NewEntry()
+		entry.SetText(strconv.Itoa(controller.CommandPrice(name)))
+		entry.OnChanged = func(s string) {
+			if price, err := strconv.Atoi(s); err == nil {
+				controller.SetCommandPrice(name, price)
+				auditor.Changed()
+			}
+		}
+		prices = append(prices, widget.NewLabel(name), entry)
+	}
+	p.panel = container.NewVBox(
+		widget.NewCheckWithData(i18n.T("gui.config.points.enable"), bind.Bool(&c.Enable)),
+		earn,
+		widget.NewLabel(i18n.T("gui.config.points.prices")),
+		container.New(layout.NewFormLayout(), prices...),
+	)
+	return p.panel
+}
@@ -0,0 +1,144 @@
+package gui
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/i18n"
+	"AynaLivePlayer/util"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"strings"
+)
+
+type roleConfig struct {
+	panel fyne.CanvasObject
+	roles []*controller.Role
+	rows  *fyne.Container
+}
+
+var roleConfigLayout = &roleConfig{}
+
+// reload roles from config if panel is created, it should be called when roles are changed outside panel
+func (r *roleConfig) reload() {
+	if r.panel == nil {
+		return
+	}
+	r.roles = controller.GetRoles()
+	r.refreshRows()
+}
+
+func (r *roleConfig) Title() string {
+	return i18n.T("gui.config.role.title")
+}
+
+func (r *roleConfig) Description() string {
+	return i18n.T("gui.config.role.description")
+}
+
+// NewRoleCheckGroup create check group of all roles for choosing roles of a permission,
+// selected roles which are not defined are kept as options.
+func NewRoleCheckGroup(selected []string, changed func(roles []string)) *widget.CheckGroup {
+	options := controller.RoleNames()
+	for _, role := range selected {
+		if !util.StringSliceContains(options, role) {
+			options = append(options, role)
+		}
+	}
+	group := widget.NewCheckGroup(options, nil)
+	group.Horizontal = true
+	group.SetSelected(append([]string{}, selected...))
+	group.OnChanged = changed
+	return group
+}
+
+func (r *roleConfig) createRow(role *controller.Role) fyne.CanvasObject {
+	name := widget.NewEntryWithData(binding.BindString(&role.Name))
+	medalName := widget.NewEntryWithData(binding.BindString(&role.MedalName))
+	medalName.SetPlaceHolder(i18n.T("gui.config.role.medal_name.any"))
+	medalLevel := widget.NewEntryWithData(binding.IntToString(binding.BindInt(&role.MedalLevel)))
+	guardLevel := widget.NewEntryWithData(binding.IntToString(binding.BindInt(&role.GuardLevel)))
+	uids := widget.NewEntry()
+	uids.SetText(strings.Join(role.Uids, ","))
+	uids.SetPlaceHolder("uid1,uid2")
+	uids.OnChanged = func(s string) {
+		role.Uids = strings.Split(s, ",")
+	}
+	admin := widget.NewCheckWithData(i18n.T("gui.config.role.admin"), binding.BindBool(&role.Admin))
+	deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		for i, x := range r.roles {
+			if x == role {
+				r.roles = append(r.roles[:i], r.roles[i+1:]...)
+				break
+			}
+		}
+		r.refreshRows()
+	})
+	return container.NewBorder(nil, nil, nil, container.NewHBox(admin, deleteBtn),
+		container.NewGridWithColumns(5, name, medalName, medalLevel, guardLevel, uids))
+}
+
+func (r *roleConfig) refreshRows() {
+	rows := make([]fyne.CanvasObject, 0, len(r.roles))
+	for _, role := range r.roles {
+		rows = append(rows, r.createRow(role))
+	}
+	r.rows.Objects = rows
+	r.rows.Refresh()
+}
+
+func (r *roleConfig) save() {
+	names := make([]string, 0, len(r.roles))
+	for _, role := range r.roles {
+		role.Name = strings.TrimSpace(role.Name)
+		role.MedalName = strings.TrimSpace(role.MedalName)
+		uids := make([]string, 0, len(role.Uids))
+		for _, uid := range role.Uids {
+			if uid = strings.TrimSpace(uid); uid != "" {
+				uids = append(uids, uid)
+			}
+		}
+		role.Uids = uids
+		if err := role.Validate(); err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %s", err, role.Name), MainWindow)
+			return
+		}
+		if util.StringSliceContains(names, role.Name) {
+			dialog.ShowError(fmt.Errorf("%s: %s", i18n.T("gui.config.role.duplicated"), role.Name), MainWindow)
+			return
+		}
+		names = append(names, role.Name)
+	}
+	controller.SetRoles(r.roles)
+	controller.Audit(controller.ActorGUI, controller.AuditConfig, "roles %s", strings.Join(names, ","))
+	r.refreshRows()
+}
+
+func (r *roleConfig) CreatePanel() fyne.CanvasObject {
+	if r.panel != nil {
+		return r.panel
+	}
+	r.roles = controller.GetRoles()
+	r.rows = container.NewVBox()
+	r.refreshRows()
+	addBtn := widget.NewButtonWithIcon(i18n.T("gui.config.role.add"), theme.ContentAddIcon(), func() {
+		r.roles = append(r.roles, &controller.Role{Name: fmt.Sprintf("role%d", len(r.roles)+1)})
+		r.refreshRows()
+	})
+	saveBtn := widget.NewButtonWithIcon(i18n.T("gui.config.role.save"), theme.DocumentSaveIcon(), r.save)
+	r.panel = container.NewVBox(
+		widget.NewLabel(i18n.T("gui.config.role.builtin")+": "+strings.Join(controller.BuiltinRoles, ", ")),
+		container.NewGridWithColumns(5,
+			widget.NewLabel(i18n.T("gui.config.role.name")),
+			widget.NewLabel(i18n.T("gui.config.role.medal_name")),
+			widget.NewLabel(i18n.T("gui.config.role.medal_level")),
+			widget.NewLabel(i18n.T("gui.config.role.guard_level")),
+			widget.NewLabel(i18n.T("gui.config.role.uids"))),
+		r.rows,
+		container.NewHBox(addBtn, saveBtn),
+	)
+	return r.panel
+}
@@ -0,0 +1,156 @@
+package gui
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/i18n"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"strings"
+	"time"
+)
+
+// number of upcoming transitions shown in preview
+const schedulePreviewSize = 8
+
+type scheduleConfig struct {
+	panel   fyne.CanvasObject
+	rules   []*controller.ScheduleRule
+	rows    *fyne.Container
+	preview *widget.Label
+}
+
+func (s *scheduleConfig) Title() string {
+	return i18n.T("gui.config.schedule.title")
+}
+
+func (s *scheduleConfig) Description() string {
+	return i18n.T("gui.config.schedule.description")
+}
+
+// scheduleValueOptions return suggested values of the action
+func scheduleValueOptions(action controller.ScheduleAction) []string {
+	switch action {
+	case controller.ScheduleActionPlaylist:
+		names := make([]string, len(controller.PlaylistManager))
+		for i, p := range controller.PlaylistManager {
+			names[i] = p.Name
+		}
+		return names
+	case controller.ScheduleActionVolume:
+		return []string{"100", "80", "50", "30"}
+	case controller.ScheduleActionRandom:
+		return []string{"true", "false"}
+	case controller.ScheduleActionDiange:
+		return []string{"user,privilege,admin", "privilege,admin", "admin", "none"}
+	}
+	return []string{}
+}
+
+func (s *scheduleConfig) createRow(rule *controller.ScheduleRule) fyne.CanvasObject {
+	days := widget.NewEntryWithData(binding.BindString(&rule.Days))
+	days.SetPlaceHolder(controller.ScheduleEveryDay)
+	at := widget.NewEntryWithData(binding.BindString(&rule.Time))
+	at.SetPlaceHolder("HH:MM")
+	value := widget.NewSelectEntry(scheduleValueOptions(rule.Action))
+	value.Bind(binding.BindString(&rule.Value))
+	actions := make([]string, len(controller.ScheduleActions))
+	for i, a := range controller.ScheduleActions {
+		actions[i] = string(a)
+	}
+	action := widget.NewSelect(actions, func(a string) {
+		rule.Action = controller.ScheduleAction(a)
+		value.SetOptions(scheduleValueOptions(rule.Action))
+	})
+	action.SetSelected(string(rule.Action))
+	deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		for i, r := range s.rules {
+			if r == rule {
+				s.rules = append(s.rules[:i], s.rules[i+1:]...)
+				break
+			}
+		}
+		s.refreshRows()
+	})
+	return container.NewBorder(nil, nil, nil, deleteBtn,
+		container.NewGridWithColumns(4, days, at, action, value))
+}
+
+func (s *scheduleConfig) refreshRows() {
+	rows := make([]fyne.CanvasObject, 0, len(s.rules))
+	for _, r := range s.rules {
+		rows = append(rows, s.createRow(r))
+	}
+	s.rows.Objects = rows
+	s.rows.Refresh()
+}
+
+func (s *scheduleConfig) refreshPreview() {
+	var sb strings.Builder
+	for _, t := range controller.UpcomingTransitions(time.Now(), schedulePreviewSize) {
+		sb.WriteString(fmt.Sprintf("%s  %s = %s\n",
+			t.Time.Format("Mon 01-02 15:04"), t.Rule.Action, t.Rule.Value))
+	}
+	if sb.Len() == 0 {
+		sb.WriteString(i18n.T("gui.config.schedule.preview.empty"))
+	}
+	s.preview.SetText(sb.String())
+}
+
+func (s *scheduleConfig) save() {
+	for _, r := range s.rules {
+		r.Days = strings.TrimSpace(r.Days)
+		r.Time = strings.TrimSpace(r.Time)
+		r.Value = strings.TrimSpace(r.Value)
+		if r.Days == "" {
+			r.Days = controller.ScheduleEveryDay
+		}
+		if err := r.Validate(); err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %s", err, r), MainWindow)
+			return
+		}
+	}
+	controller.SetScheduleRules(s.rules)
+	controller.Audit(controller.ActorGUI, controller.AuditConfig, "%d schedule rules", len(s.rules))
+	s.refreshRows()
+	s.refreshPreview()
+}
+
+func (s *scheduleConfig) CreatePanel() fyne.CanvasObject {
+	if s.panel != nil {
+		return s.panel
+	}
+	s.rules = controller.GetScheduleRules()
+	s.rows = container.NewVBox()
+	s.preview = widget.NewLabel("")
+	s.refreshRows()
+	s.refreshPreview()
+	addBtn := widget.NewButtonWithIcon(i18n.T("gui.config.schedule.add"), theme.ContentAddIcon(), func() {
+		s.rules = append(s.rules, &controller.ScheduleRule{
+			Days:   controller.ScheduleEveryDay,
+			Time:   "00:00",
+			Action: controller.ScheduleActionPlaylist,
+		})
+		s.refreshRows()
+	})
+	saveBtn := widget.NewButtonWithIcon(i18n.T("gui.config.schedule.save"), theme.DocumentSaveIcon(), s.save)
+	s.panel = container.NewVBox(
+		widget.NewCheckWithData(i18n.T("gui.config.schedule.enable"), binding.BindBool(&config.Schedule.Enable)),
+		container.NewGridWithColumns(4,
+			widget.NewLabel(i18n.T("gui.config.schedule.days")),
+			widget.NewLabel(i18n.T("gui.config.schedule.time")),
+			widget.NewLabel(i18n.T("gui.config.schedule.action")),
+			widget.NewLabel(i18n.T("gui.config.schedule.value"))),
+		s.rows,
+		container.NewHBox(addBtn, saveBtn),
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("gui.config.schedule.preview")),
+		s.preview,
+	)
+	return s.panel
+}
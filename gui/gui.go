@@ -23,7 +23,7 @@ type ConfigLayout interface {
 
 var App fyne.App
 var MainWindow fyne.Window
-var ConfigList = []ConfigLayout{&bascicConfig{}}
+var ConfigList = []ConfigLayout{&bascicConfig{}, &liveRoomConfig{}, &scheduleConfig{}, &pointsConfig{}, roleConfigLayout}
 
 func l() *logrus.Entry {
 	return logger.Logger.WithField("Module", MODULE_GUI)
@@ -48,9 +48,21 @@ func Initialize() {
 		container.NewTabItem(i18n.T("gui.tab.playlist"),
 			newPaddedBoarder(nil, nil, createPlaylists(), nil, createPlaylistMedias()),
 		),
+		container.NewTabItem(i18n.T("gui.tab.approval"),
+			newPaddedBoarder(nil, nil, nil, nil, createApprovalList()),
+		),
 		container.NewTabItem(i18n.T("gui.tab.history"),
 			newPaddedBoarder(nil, nil, nil, nil, createHistoryList()),
 		),
+		container.NewTabItem(i18n.T("gui.tab.setlist"),
+			newPaddedBoarder(nil, nil, nil, nil, createSetlist()),
+		),
+		container.NewTabItem(i18n.T("gui.tab.stats"),
+			newPaddedBoarder(nil, nil, nil, nil, createStatsList()),
+		),
+		container.NewTabItem(i18n.T("gui.tab.audit"),
+			newPaddedBoarder(nil, nil, nil, nil, createAuditList()),
+		),
 		container.NewTabItem(i18n.T("gui.tab.config"),
 			newPaddedBoarder(nil, nil, nil, nil, createConfigLayout()),
 		),
@@ -0,0 +1,43 @@
+package gui
+
+import "fyne.io/fyne/v2/data/binding"
+
+// ConfigBinder bind config fields and notify listeners when they are changed,
+// e.g. update commands and record the change in audit log.
+type ConfigBinder struct {
+	listeners []binding.DataListener
+}
+
+func NewConfigBinder(listeners ...binding.DataListener) *ConfigBinder {
+	return &ConfigBinder{listeners: listeners}
+}
+
+func (b *ConfigBinder) addListeners(data binding.DataItem) {
+	for _, listener := range b.listeners {
+		data.AddListener(listener)
+	}
+}
+
+func (b *ConfigBinder) String(value *string) binding.String {
+	data := binding.BindString(value)
+	b.addListeners(data)
+	return data
+}
+
+func (b *ConfigBinder) Int(value *int) binding.Int {
+	data := binding.BindInt(value)
+	b.addListeners(data)
+	return data
+}
+
+func (b *ConfigBinder) Float(value *float64) binding.Float {
+	data := binding.BindFloat(value)
+	b.addListeners(data)
+	return data
+}
+
+func (b *ConfigBinder) Bool(value *bool) binding.Bool {
+	data := binding.BindBool(value)
+	b.addListeners(data)
+	return data
+}
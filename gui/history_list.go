@@ -4,26 +4,135 @@ import (
 	"AynaLivePlayer/controller"
 	"AynaLivePlayer/event"
 	"AynaLivePlayer/i18n"
-	"AynaLivePlayer/player"
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"io"
+	"strings"
+	"time"
 )
 
+const historyPageSize = 100
+
+const historyDateLayout = "2006-01-02"
+
 type HistoryContainer struct {
-	Playlist *player.Playlist
-	List     *widget.List
+	Filter    controller.HistoryFilter
+	Records   []*controller.PlayRecord
+	Page      int
+	List      *widget.List
+	PageLabel *widget.Label
+}
+
+var History = &HistoryContainer{}
+
+func (h *HistoryContainer) pageCount() int {
+	return (len(h.Records) + historyPageSize - 1) / historyPageSize
+}
+
+// pageRecords return records in current page
+func (h *HistoryContainer) pageRecords() []*controller.PlayRecord {
+	start := h.Page * historyPageSize
+	if start >= len(h.Records) {
+		return nil
+	}
+	end := start + historyPageSize
+	if end > len(h.Records) {
+		end = len(h.Records)
+	}
+	return h.Records[start:end]
+}
+
+func (h *HistoryContainer) setPage(page int) {
+	if page >= h.pageCount() {
+		page = h.pageCount() - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	h.Page = page
+	h.PageLabel.SetText(fmt.Sprintf("%d / %d (%d)", h.Page+1, h.pageCount(), len(h.Records)))
+	h.List.Refresh()
+}
+
+// Reload query history with current filter
+func (h *HistoryContainer) Reload() {
+	h.Records = controller.Stats.Query(h.Filter)
+	h.setPage(h.Page)
 }
 
-var History = &PlaylistContainer{}
+func parseHistoryDate(value string) int64 {
+	t, err := time.ParseInLocation(historyDateLayout, strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func createHistoryFilter() fyne.CanvasObject {
+	title := widget.NewEntry()
+	title.SetPlaceHolder(i18n.T("gui.history.title"))
+	artist := widget.NewEntry()
+	artist.SetPlaceHolder(i18n.T("gui.history.artist"))
+	user := widget.NewEntry()
+	user.SetPlaceHolder(i18n.T("gui.history.user"))
+	providers := append([]string{i18n.T("gui.history.filter.all")}, controller.Stats.Providers()...)
+	provider := widget.NewSelect(providers, nil)
+	provider.SetSelectedIndex(0)
+	since := widget.NewEntry()
+	since.SetPlaceHolder(historyDateLayout)
+	until := widget.NewEntry()
+	until.SetPlaceHolder(historyDateLayout)
+	search := widget.NewButtonWithIcon(i18n.T("gui.history.filter.search"), theme.SearchIcon(), func() {
+		History.Filter = controller.HistoryFilter{
+			Title:     strings.TrimSpace(title.Text),
+			Artist:    strings.TrimSpace(artist.Text),
+			Requester: strings.TrimSpace(user.Text),
+			Since:     parseHistoryDate(since.Text),
+		}
+		if provider.SelectedIndex() > 0 {
+			History.Filter.Provider = provider.Selected
+		}
+		// until date is inclusive
+		if t := parseHistoryDate(until.Text); t > 0 {
+			History.Filter.Until = t + 24*3600
+		}
+		History.Page = 0
+		History.Reload()
+	})
+	return container.NewVBox(
+		container.NewGridWithColumns(4, title, artist, user, provider),
+		container.NewBorder(nil, nil,
+			widget.NewLabel(i18n.T("gui.history.filter.date")), search,
+			container.NewGridWithColumns(2, since, until)),
+	)
+}
+
+func exportHistory(export func(w io.Writer, records []*controller.PlayRecord) error) {
+	records := History.Records
+	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		if err = export(writer, records); err != nil {
+			l().Warnf("export history failed: %s", err)
+			dialog.ShowError(err, MainWindow)
+		}
+	}, MainWindow)
+}
 
 func createHistoryList() fyne.CanvasObject {
-	History.Playlist = controller.History
 	History.List = widget.NewList(
 		func() int {
-			return History.Playlist.Size()
+			return len(History.pageRecords())
 		},
 		func() fyne.CanvasObject {
 			return container.NewBorder(nil, nil,
@@ -32,45 +141,71 @@ func createHistoryList() fyne.CanvasObject {
 					widget.NewButtonWithIcon("", theme.MediaPlayIcon(), nil),
 					widget.NewButtonWithIcon("", theme.ContentAddIcon(), nil),
 				),
-				container.NewGridWithColumns(3,
+				container.NewGridWithColumns(4,
 					newLabelWithWrapping("title", fyne.TextTruncate),
 					newLabelWithWrapping("artist", fyne.TextTruncate),
-					newLabelWithWrapping("user", fyne.TextTruncate)))
+					newLabelWithWrapping("user", fyne.TextTruncate),
+					newLabelWithWrapping("time", fyne.TextTruncate)))
 		},
 		func(id widget.ListItemID, object fyne.CanvasObject) {
-			m := History.Playlist.Playlist[History.Playlist.Size()-id-1]
+			records := History.pageRecords()
+			if id >= len(records) {
+				return
+			}
+			r := records[id]
 			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[0].(*widget.Label).SetText(
-				m.Title)
+				r.Title)
 			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[1].(*widget.Label).SetText(
-				m.Artist)
+				r.Artist)
 			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[2].(*widget.Label).SetText(
-				m.ToUser().Name)
-			object.(*fyne.Container).Objects[1].(*widget.Label).SetText(fmt.Sprintf("%d", id))
+				r.Requester)
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[3].(*widget.Label).SetText(
+				time.Unix(r.Time, 0).Format("2006-01-02 15:04"))
+			object.(*fyne.Container).Objects[1].(*widget.Label).SetText(
+				fmt.Sprintf("%d", History.Page*historyPageSize+id))
 			btns := object.(*fyne.Container).Objects[2].(*fyne.Container).Objects
 			btns[0].(*widget.Button).OnTapped = func() {
-				controller.Play(controller.ToHistoryMedia(m))
+				controller.Play(r.ToMedia())
 			}
 			btns[1].(*widget.Button).OnTapped = func() {
-				controller.UserPlaylist.Push(controller.ToHistoryMedia(m))
+				pushUserPlaylist(r.ToMedia())
 			}
 		})
+	History.PageLabel = widget.NewLabel("")
+	pager := container.NewHBox(
+		widget.NewButtonWithIcon("", theme.NavigateBackIcon(), func() {
+			History.setPage(History.Page - 1)
+		}),
+		History.PageLabel,
+		widget.NewButtonWithIcon("", theme.NavigateNextIcon(), func() {
+			History.setPage(History.Page + 1)
+		}),
+		widget.NewButtonWithIcon(i18n.T("gui.history.export.csv"), theme.DocumentSaveIcon(), func() {
+			exportHistory(controller.ExportHistoryCSV)
+		}),
+		widget.NewButtonWithIcon(i18n.T("gui.history.export.json"), theme.DocumentSaveIcon(), func() {
+			exportHistory(controller.ExportHistoryJSON)
+		}),
+	)
+	History.Reload()
 	registerHistoryHandler()
 	return container.NewBorder(
-		container.NewBorder(nil, nil,
-			widget.NewLabel("#"), widget.NewLabel(i18n.T("gui.history.operation")),
-			container.NewGridWithColumns(3,
-				widget.NewLabel(i18n.T("gui.history.title")),
-				widget.NewLabel(i18n.T("gui.history.artist")),
-				widget.NewLabel(i18n.T("gui.history.user")))),
-		nil, nil, nil,
+		container.NewVBox(
+			createHistoryFilter(),
+			container.NewBorder(nil, nil,
+				widget.NewLabel("#"), widget.NewLabel(i18n.T("gui.history.operation")),
+				container.NewGridWithColumns(4,
+					widget.NewLabel(i18n.T("gui.history.title")),
+					widget.NewLabel(i18n.T("gui.history.artist")),
+					widget.NewLabel(i18n.T("gui.history.user")),
+					widget.NewLabel(i18n.T("gui.history.time"))))),
+		pager, nil, nil,
 		History.List,
 	)
 }
 
 func registerHistoryHandler() {
-	History.Playlist.Handler.RegisterA(player.EventPlaylistUpdate, "gui.history.update", func(event *event.Event) {
-		History.Playlist.Lock.RLock()
-		History.List.Refresh()
-		History.Playlist.Lock.RUnlock()
+	controller.Stats.Handler.RegisterA(controller.EventHistoryUpdate, "gui.history.update", func(event *event.Event) {
+		History.Reload()
 	})
 }
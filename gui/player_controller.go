@@ -13,6 +13,9 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/aynakeya/go-mpv"
+	"math"
+	"sync"
+	"time"
 )
 
 type PlayControllerContainer struct {
@@ -29,6 +32,47 @@ type PlayControllerContainer struct {
 	LrcWindowOpen bool
 	CurrentTime   *widget.Label
 	TotalTime     *widget.Label
+	volumeTimer   *time.Timer
+	// volumeAudited is the volume before dragging slider, changes not made by slider are synced to it
+	volumeAudited float64
+	volumeLock    sync.Mutex
+}
+
+// volume changes are recorded in audit log after slider stops for a while
+const volumeAuditDelay = 2 * time.Second
+
+// auditVolume record the final volume when dragging slider stopped,
+// nothing is recorded if it is dragged back to the volume before.
+func (p *PlayControllerContainer) auditVolume(volume float64) {
+	p.volumeLock.Lock()
+	defer p.volumeLock.Unlock()
+	if p.volumeTimer != nil {
+		p.volumeTimer.Stop()
+	}
+	p.volumeTimer = time.AfterFunc(volumeAuditDelay, func() {
+		p.volumeLock.Lock()
+		defer p.volumeLock.Unlock()
+		p.volumeTimer = nil
+		if math.Round(volume) == math.Round(p.volumeAudited) {
+			return
+		}
+		p.volumeAudited = volume
+		controller.Audit(controller.ActorGUI, controller.AuditVolume, "%.0f", volume)
+	})
+}
+
+// syncVolume update slider with volume reported by player, which might be set at startup
+// or by schedule. It is not audited as a gui change.
+func (p *PlayControllerContainer) syncVolume(volume float64) {
+	p.volumeLock.Lock()
+	// volume set by dragging slider is reported back as well, keep the volume before dragging
+	if p.volumeTimer == nil {
+		p.volumeAudited = volume
+	}
+	p.volumeLock.Unlock()
+	// set Value directly since SetValue calls OnChanged
+	p.Volume.Value = volume
+	p.Volume.Refresh()
 }
 
 func (p *PlayControllerContainer) SetDefaultCover() {
@@ -82,7 +126,7 @@ func registerPlayControllerHandler() {
 		controller.Toggle()
 	}
 	PlayController.ButtonNext.OnTapped = func() {
-		controller.PlayNext()
+		controller.Skip(controller.ActorGUI)
 	}
 
 	PlayController.ButtonLrc.OnTapped = func() {
@@ -163,18 +207,17 @@ func registerPlayControllerHandler() {
 	if controller.MainPlayer.ObserveProperty("volume", func(property *mpv.EventProperty) {
 		l().Trace("receive volume change event", *property)
 		if property.Data == nil {
-			PlayController.Volume.Value = 0
+			PlayController.syncVolume(0)
 		} else {
-			PlayController.Volume.Value = property.Data.(mpv.Node).Value.(float64)
+			PlayController.syncVolume(property.Data.(mpv.Node).Value.(float64))
 		}
-
-		PlayController.Volume.Refresh()
 	}) != nil {
 		l().Error("fail to register handler for progress bar with property percent-pos")
 	}
 
 	PlayController.Volume.OnChanged = func(f float64) {
 		controller.SetVolume(f)
+		PlayController.auditVolume(f)
 	}
 
 	controller.MainPlayer.EventHandler.RegisterA(player.EventPlay, "gui.player.updateinfo", func(event *event.Event) {
@@ -25,10 +25,10 @@ func (b *playlistOperationButton) Tapped(e *fyne.PointEvent) {
 func newPlaylistOperationButton() *playlistOperationButton {
 	b := &playlistOperationButton{Index: 0}
 	deleteItem := fyne.NewMenuItem(i18n.T("gui.player.playlist.op.delete"), func() {
-		controller.UserPlaylist.Delete(b.Index)
+		controller.RemoveRequest(b.Index, controller.ActorGUI)
 	})
 	topItem := fyne.NewMenuItem(i18n.T("gui.player.playlist.op.top"), func() {
-		controller.UserPlaylist.Move(b.Index, 0)
+		controller.BumpRequest(b.Index, controller.ActorGUI)
 	})
 	m := fyne.NewMenu("", deleteItem, topItem)
 	b.menu = m
@@ -38,6 +38,12 @@ func newPlaylistOperationButton() *playlistOperationButton {
 	return b
 }
 
+// pushUserPlaylist add media to the end of user playlist by gui
+func pushUserPlaylist(media *player.Media) {
+	controller.UserPlaylist.Push(media)
+	controller.Audit(controller.ActorGUI, controller.AuditQueueAdd, "%s - %s", media.Title, media.Artist)
+}
+
 type PlaylistContainer struct {
 	Playlist *player.Playlist
 	List     *widget.List
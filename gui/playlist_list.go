@@ -4,6 +4,7 @@ import (
 	"AynaLivePlayer/config"
 	"AynaLivePlayer/controller"
 	"AynaLivePlayer/i18n"
+	"AynaLivePlayer/util"
 	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -11,6 +12,8 @@ import (
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"strconv"
+	"strings"
 )
 
 type PlaylistManagerContainer struct {
@@ -22,13 +25,57 @@ type PlaylistManagerContainer struct {
 	SetAsSystemBtn        *widget.Button
 	RefreshBtn            *widget.Button
 	CurrentSystemPlaylist *widget.Label
+	IdleCheck             *widget.Check
+	IdleWeight            *widget.Entry
+	updatingIdle          bool
 }
 
 func (p *PlaylistManagerContainer) UpdateCurrentSystemPlaylist() {
-	if config.Player.PlaylistIndex >= len(controller.PlaylistManager) {
+	total := 0
+	for i := range controller.PlaylistManager {
+		total += controller.GetSystemPlaylistWeight(i)
+	}
+	if total == 0 {
 		p.CurrentSystemPlaylist.SetText(i18n.T("gui.playlist.current.none"))
+		return
+	}
+	names := make([]string, 0)
+	for i, pl := range controller.PlaylistManager {
+		if w := controller.GetSystemPlaylistWeight(i); w > 0 {
+			names = append(names, fmt.Sprintf("%s(%d%%)", pl.Name, w*100/total))
+		}
+	}
+	p.CurrentSystemPlaylist.SetText(i18n.T("gui.playlist.current") + strings.Join(names, ", "))
+}
+
+// UpdateIdleSetting update idle rotation widgets for the selected playlist
+func (p *PlaylistManagerContainer) UpdateIdleSetting() {
+	p.updatingIdle = true
+	weight := controller.GetSystemPlaylistWeight(p.Index)
+	p.IdleCheck.SetChecked(weight > 0)
+	if weight > 0 {
+		p.IdleWeight.SetText(strconv.Itoa(weight))
 	}
-	p.CurrentSystemPlaylist.SetText(i18n.T("gui.playlist.current") + controller.PlaylistManager[config.Player.PlaylistIndex].Name)
+	p.updatingIdle = false
+}
+
+func (p *PlaylistManagerContainer) applyIdleSetting() {
+	if p.updatingIdle {
+		return
+	}
+	weight := 0
+	if p.IdleCheck.Checked {
+		weight = util.StringToInt(p.IdleWeight.Text)
+		if weight <= 0 {
+			weight = 1
+		}
+	}
+	index := p.Index
+	go func() {
+		controller.SetSystemPlaylistWeight(index, weight)
+		p.PlaylistMedia.Refresh()
+		p.UpdateCurrentSystemPlaylist()
+	}()
 }
 
 var PlaylistManager = &PlaylistManagerContainer{}
@@ -80,10 +127,12 @@ func createPlaylists() fyne.CanvasObject {
 		PlaylistManager.Playlists.Select(0)
 		PlaylistManager.Playlists.Refresh()
 		PlaylistManager.PlaylistMedia.Refresh()
+		PlaylistManager.UpdateCurrentSystemPlaylist()
 	})
 	PlaylistManager.Playlists.OnSelected = func(id widget.ListItemID) {
 		PlaylistManager.Index = id
 		PlaylistManager.PlaylistMedia.Refresh()
+		PlaylistManager.UpdateIdleSetting()
 	}
 	return container.NewHBox(
 		container.NewBorder(
@@ -108,9 +157,19 @@ func createPlaylistMedias() fyne.CanvasObject {
 			controller.SetSystemPlaylist(PlaylistManager.Index)
 			PlaylistManager.PlaylistMedia.Refresh()
 			PlaylistManager.UpdateCurrentSystemPlaylist()
+			PlaylistManager.UpdateIdleSetting()
 		})
 	PlaylistManager.CurrentSystemPlaylist = widget.NewLabel("Current: ")
 	PlaylistManager.UpdateCurrentSystemPlaylist()
+	PlaylistManager.IdleCheck = widget.NewCheck(i18n.T("gui.playlist.idle.member"), func(b bool) {
+		PlaylistManager.applyIdleSetting()
+	})
+	PlaylistManager.IdleWeight = widget.NewEntry()
+	PlaylistManager.IdleWeight.SetText("1")
+	PlaylistManager.IdleWeight.OnSubmitted = func(s string) {
+		PlaylistManager.applyIdleSetting()
+	}
+	PlaylistManager.UpdateIdleSetting()
 	PlaylistManager.PlaylistMedia = widget.NewList(
 		func() int {
 			if len(controller.PlaylistManager) == 0 {
@@ -141,11 +200,18 @@ func createPlaylistMedias() fyne.CanvasObject {
 				controller.Play(controller.ToSystemMedia(m))
 			}
 			btns[1].(*widget.Button).OnTapped = func() {
-				controller.UserPlaylist.Push(controller.ToSystemMedia(m))
+				pushUserPlaylist(controller.ToSystemMedia(m))
 			}
 		})
+	idleSetting := container.NewHBox(
+		PlaylistManager.IdleCheck,
+		widget.NewLabel(i18n.T("gui.playlist.idle.weight")),
+		container.NewGridWrap(fyne.NewSize(64, PlaylistManager.IdleWeight.MinSize().Height), PlaylistManager.IdleWeight))
 	return container.NewBorder(
-		container.NewHBox(PlaylistManager.RefreshBtn, PlaylistManager.SetAsSystemBtn, PlaylistManager.CurrentSystemPlaylist), nil,
+		container.NewVBox(
+			container.NewHBox(PlaylistManager.RefreshBtn, PlaylistManager.SetAsSystemBtn, PlaylistManager.CurrentSystemPlaylist),
+			idleSetting),
+		nil,
 		nil, nil,
 		PlaylistManager.PlaylistMedia)
 }
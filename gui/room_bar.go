@@ -6,6 +6,7 @@ import (
 	"AynaLivePlayer/event"
 	"AynaLivePlayer/i18n"
 	"AynaLivePlayer/liveclient"
+	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
@@ -22,6 +23,7 @@ var RoomController = &RoomControllerContainer{}
 
 func createRoomController() fyne.CanvasObject {
 	RoomController.Input = widget.NewSelectEntry(config.LiveRoom.History)
+	RoomController.Input.SetPlaceHolder(i18n.T("gui.room.id.placeholder"))
 	RoomController.ConnectBtn = widget.NewButton(i18n.T("gui.room.btn.connect"), func() {
 		RoomController.ConnectBtn.Disable()
 		controller.SetDanmuClient(RoomController.Input.Text)
@@ -34,8 +36,14 @@ func createRoomController() fyne.CanvasObject {
 		RoomController.Input.SetOptions(config.LiveRoom.History)
 		controller.LiveClient.Handler().RegisterA(liveclient.EventStatusChange, "gui.liveclient.status", func(event *event.Event) {
 			d := event.Data.(liveclient.StatusChangeEvent)
-			if d.Connected {
+			if d.Connected && d.Attempt > 0 {
+				RoomController.Status.SetText(fmt.Sprintf(i18n.T("gui.room.status.reconnected"), d.Attempt))
+			} else if d.Connected {
 				RoomController.Status.SetText(i18n.T("gui.room.status.connected"))
+			} else if d.Error != nil {
+				RoomController.Status.SetText(i18n.T("gui.room.status.failed"))
+			} else if d.Reconnecting {
+				RoomController.Status.SetText(fmt.Sprintf(i18n.T("gui.room.status.reconnecting"), d.Attempt))
 			} else {
 				RoomController.Status.SetText(i18n.T("gui.room.status.disconnected"))
 			}
@@ -1,21 +1,157 @@
 package gui
 
 import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/i18n"
+	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"time"
 )
 
 type RoomLoggerContainer struct {
+	Filter  string
+	Entries []*controller.DanmuLogEntry
+	Paused  bool
+	List    *widget.List
 }
 
 var RoomLogger = &RoomLoggerContainer{}
 
+// danmuLabel is a label which shows moderation actions of the danmu when right clicked
+type danmuLabel struct {
+	widget.Label
+	entry *controller.DanmuLogEntry
+}
+
+func newDanmuLabel() *danmuLabel {
+	d := &danmuLabel{}
+	d.Wrapping = fyne.TextTruncate
+	d.ExtendBaseWidget(d)
+	return d
+}
+
+func (d *danmuLabel) TappedSecondary(e *fyne.PointEvent) {
+	if d.entry == nil {
+		return
+	}
+	widget.ShowPopUpMenuAtPosition(createDanmuMenu(d.entry), fyne.CurrentApp().Driver().CanvasForObject(d), e.AbsolutePosition)
+}
+
+func createDanmuMenu(entry *controller.DanmuLogEntry) *fyne.Menu {
+	user := entry.User
+	whitelist := make([]*fyne.MenuItem, 0)
+	for _, role := range controller.GetRoles() {
+		name := role.Name
+		whitelist = append(whitelist, fyne.NewMenuItem(name, func() {
+			if err := controller.AddRoleUid(name, user.Uid); err != nil {
+				dialog.ShowError(err, MainWindow)
+				return
+			}
+			controller.Audit(controller.ActorGUI, controller.AuditConfig, "add %s(%s) to whitelist of role %s", user.Username, user.Uid, name)
+			roleConfigLayout.reload()
+		}))
+	}
+	items := []*fyne.MenuItem{
+		fyne.NewMenuItem(i18n.T("gui.room.logger.ban"), func() {
+			controller.AddBlacklistRule(&controller.BlacklistRule{
+				Type:  controller.BlacklistUser,
+				Value: user.Uid,
+				Note:  user.Username,
+			}, controller.ActorGUI)
+		}),
+		fyne.NewMenuItem(i18n.T("gui.room.logger.remove_requests"), func() {
+			controller.RemoveUserRequests(user.Uid, controller.ActorGUI)
+		}),
+	}
+	// uid can only be added to whitelist of custom roles
+	if len(whitelist) > 0 {
+		whitelistItem := fyne.NewMenuItem(i18n.T("gui.room.logger.whitelist"), nil)
+		whitelistItem.ChildMenu = fyne.NewMenu("", whitelist...)
+		items = append(items, whitelistItem)
+	}
+	return fyne.NewMenu("", items...)
+}
+
+// Reload query danmu log with current filter, scroll to latest danmu if not paused
+func (r *RoomLoggerContainer) Reload() {
+	r.Entries = controller.DanmuLog.Query(r.Filter)
+	r.List.Refresh()
+	if !r.Paused {
+		r.List.ScrollToBottom()
+	}
+}
+
+func exportDanmuLog() {
+	entries := RoomLogger.Entries
+	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		if err = controller.ExportDanmuLog(writer, entries); err != nil {
+			l().Warnf("export danmu log failed: %s", err)
+			dialog.ShowError(err, MainWindow)
+		}
+	}, MainWindow)
+}
+
 func createRoomLogger() fyne.CanvasObject {
-	//b := NewAsyncButton("ceshi", func() {
-	//	time.Sleep(time.Second * 5)
-	//})
-	return container.NewVBox(
-		widget.NewLabel("广告位招租"),
+	RoomLogger.List = widget.NewList(
+		func() int {
+			return len(RoomLogger.Entries)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil,
+				widget.NewLabel("time"),
+				widget.NewLabel("result"),
+				newDanmuLabel())
+		},
+		func(id widget.ListItemID, object fyne.CanvasObject) {
+			if id >= len(RoomLogger.Entries) {
+				return
+			}
+			e := RoomLogger.Entries[id]
+			text := fmt.Sprintf("%s: %s", e.User.Username, e.Message)
+			if e.User.Medal.Name != "" {
+				text = fmt.Sprintf("[%s %d] %s", e.User.Medal.Name, e.User.Medal.Level, text)
+			}
+			label := object.(*fyne.Container).Objects[0].(*danmuLabel)
+			label.entry = e
+			label.SetText(text)
+			object.(*fyne.Container).Objects[1].(*widget.Label).SetText(
+				time.Unix(e.Time, 0).Format("15:04:05"))
+			result := ""
+			if e.Command != "" {
+				result = fmt.Sprintf("%s: %s", e.Command, e.Result)
+			}
+			object.(*fyne.Container).Objects[2].(*widget.Label).SetText(result)
+		})
+	filter := widget.NewEntry()
+	filter.SetPlaceHolder(i18n.T("gui.room.logger.filter"))
+	filter.OnChanged = func(s string) {
+		RoomLogger.Filter = s
+		RoomLogger.Reload()
+	}
+	pause := widget.NewCheck(i18n.T("gui.room.logger.pause"), func(b bool) {
+		RoomLogger.Paused = b
+	})
+	export := widget.NewButtonWithIcon(i18n.T("gui.room.logger.export"), theme.DocumentSaveIcon(), exportDanmuLog)
+	RoomLogger.Reload()
+	controller.DanmuLog.Handler.RegisterA(controller.EventDanmuLogUpdate, "gui.room.logger", func(event *event.Event) {
+		RoomLogger.Reload()
+	})
+	return container.NewBorder(
+		container.NewBorder(nil, nil, nil, container.NewHBox(pause, export), filter),
+		nil, nil, nil,
+		RoomLogger.List,
 	)
 }
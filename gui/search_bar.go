@@ -5,9 +5,11 @@ import (
 	"AynaLivePlayer/controller"
 	"AynaLivePlayer/i18n"
 	"AynaLivePlayer/player"
+	"AynaLivePlayer/util"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
+	"sync"
 )
 
 type SearchBarContainer struct {
@@ -15,29 +17,47 @@ type SearchBarContainer struct {
 	Button    *widget.Button
 	UseSource *widget.CheckGroup
 	Items     []*player.Media
+	searchId  int
+	lock      sync.Mutex
 }
 
 var SearchBar = &SearchBarContainer{}
 
+// Search start searching in background, results are shown as soon as any provider returns.
+// results of previous search are ignored once a new search starts.
+func (b *SearchBarContainer) Search() {
+	keyword := b.Input.Text
+	// keep priority order of providers
+	providers := make([]string, 0, len(b.UseSource.Selected))
+	for _, p := range config.Provider.Priority {
+		if util.StringSliceContains(b.UseSource.Selected, p) {
+			providers = append(providers, p)
+		}
+	}
+	b.lock.Lock()
+	b.searchId++
+	id := b.searchId
+	b.lock.Unlock()
+	SearchResult.Items = []*player.Media{}
+	SearchResult.List.Refresh()
+	go func() {
+		_, _ = controller.SearchStream(keyword, providers, func(items []*player.Media) {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			if id != b.searchId {
+				return
+			}
+			controller.ApplyUser(items, player.SystemUser)
+			SearchResult.Items = items
+			SearchResult.List.Refresh()
+		})
+	}()
+}
+
 func createSearchBar() fyne.CanvasObject {
 	SearchBar.Input = widget.NewEntry()
 	SearchBar.Input.SetPlaceHolder(i18n.T("gui.search.placeholder"))
-	SearchBar.Button = widget.NewButton(i18n.T("gui.search.search"), nil)
-	SearchBar.Button.OnTapped = createAsyncOnTapped(SearchBar.Button, func() {
-		keyword := SearchBar.Input.Text
-		s := make([]string, len(SearchBar.UseSource.Selected))
-
-		copy(s, SearchBar.UseSource.Selected)
-		items := make([]*player.Media, 0)
-		for _, p := range s {
-			if r, err := controller.SearchWithProvider(keyword, p); err == nil {
-				items = append(items, r...)
-			}
-		}
-		controller.ApplyUser(items, player.SystemUser)
-		SearchResult.Items = items
-		SearchResult.List.Refresh()
-	})
+	SearchBar.Button = widget.NewButton(i18n.T("gui.search.search"), SearchBar.Search)
 	s := make([]string, len(config.Provider.Priority))
 	copy(s, config.Provider.Priority)
 
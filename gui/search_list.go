@@ -49,7 +49,7 @@ func createSearchList() fyne.CanvasObject {
 				controller.Play(SearchResult.Items[id])
 			}
 			btns[1].(*widget.Button).OnTapped = func() {
-				controller.UserPlaylist.Push(SearchResult.Items[id])
+				pushUserPlaylist(SearchResult.Items[id])
 			}
 		})
 	return container.NewBorder(
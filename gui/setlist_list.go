@@ -0,0 +1,130 @@
+package gui
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/i18n"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"io"
+	"strings"
+	"time"
+)
+
+type SetlistContainer struct {
+	List   *widget.List
+	Status *widget.Label
+}
+
+var SetlistView = &SetlistContainer{}
+
+func (s *SetlistContainer) Refresh() {
+	setlist := controller.CurrentSetlist
+	switch {
+	case setlist.Start == 0:
+		s.Status.SetText(i18n.T("gui.setlist.status.none"))
+	case setlist.Running():
+		s.Status.SetText(fmt.Sprintf(i18n.T("gui.setlist.status.running"),
+			time.Unix(setlist.Start, 0).Format("2006-01-02 15:04:05"), setlist.Size()))
+	default:
+		s.Status.SetText(fmt.Sprintf(i18n.T("gui.setlist.status.ended"),
+			time.Unix(setlist.Start, 0).Format("2006-01-02 15:04:05"), setlist.Size()))
+	}
+	s.List.Refresh()
+}
+
+func exportSetlist(export func(w io.Writer) error) {
+	dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, MainWindow)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+		if err = export(writer); err != nil {
+			l().Warnf("export setlist failed: %s", err)
+			dialog.ShowError(err, MainWindow)
+		}
+	}, MainWindow)
+}
+
+func createSetlist() fyne.CanvasObject {
+	SetlistView.Status = widget.NewLabel("")
+	SetlistView.List = widget.NewList(
+		func() int {
+			return controller.CurrentSetlist.Size()
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil,
+				widget.NewLabel("00:00:00"), nil,
+				container.NewGridWithColumns(3,
+					newLabelWithWrapping("title", fyne.TextTruncate),
+					newLabelWithWrapping("artist", fyne.TextTruncate),
+					newLabelWithWrapping("user", fyne.TextTruncate)))
+		},
+		func(id widget.ListItemID, object fyne.CanvasObject) {
+			e := controller.CurrentSetlist.Get(id)
+			if e == nil {
+				return
+			}
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[0].(*widget.Label).SetText(e.Title)
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[1].(*widget.Label).SetText(e.Artist)
+			object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[2].(*widget.Label).SetText(e.Requester)
+			object.(*fyne.Container).Objects[1].(*widget.Label).SetText(
+				fmt.Sprintf("%02d:%02d:%02d", e.Offset/3600, e.Offset/60%60, e.Offset%60))
+		})
+	startBtn := widget.NewButtonWithIcon(i18n.T("gui.setlist.start"), theme.MediaRecordIcon(), func() {
+		if !controller.CurrentSetlist.Running() {
+			controller.StartSession(time.Now(), true)
+			return
+		}
+		dialog.ShowConfirm(i18n.T("gui.setlist.start"), i18n.T("gui.setlist.start.confirm"), func(b bool) {
+			if b {
+				controller.StartSession(time.Now(), true)
+			}
+		}, MainWindow)
+	})
+	endBtn := widget.NewButtonWithIcon(i18n.T("gui.setlist.end"), theme.MediaStopIcon(), controller.EndSession)
+	copyBtn := widget.NewButtonWithIcon(i18n.T("gui.setlist.copy"), theme.ContentCopyIcon(), func() {
+		var sb strings.Builder
+		_ = controller.CurrentSetlist.ExportChapters(&sb)
+		MainWindow.Clipboard().SetContent(sb.String())
+	})
+	exportMenu := fyne.NewMenu("",
+		fyne.NewMenuItem(i18n.T("gui.setlist.export.chapters"), func() {
+			exportSetlist(controller.CurrentSetlist.ExportChapters)
+		}),
+		fyne.NewMenuItem("CSV", func() {
+			exportSetlist(controller.CurrentSetlist.ExportCSV)
+		}),
+		fyne.NewMenuItem("JSON", func() {
+			exportSetlist(controller.CurrentSetlist.ExportJSON)
+		}),
+	)
+	exportBtn := newContextMenuButton(i18n.T("gui.setlist.export"), exportMenu)
+	exportBtn.SetIcon(theme.DocumentSaveIcon())
+	controller.EventHandler.RegisterA(controller.EventSetlistUpdate, "gui.setlist.update", func(event *event.Event) {
+		SetlistView.Refresh()
+	})
+	SetlistView.Refresh()
+	return container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, nil,
+				container.NewHBox(startBtn, endBtn, copyBtn, exportBtn),
+				SetlistView.Status),
+			container.NewBorder(nil, nil,
+				widget.NewLabel(i18n.T("gui.setlist.offset")), nil,
+				container.NewGridWithColumns(3,
+					widget.NewLabel(i18n.T("gui.history.title")),
+					widget.NewLabel(i18n.T("gui.history.artist")),
+					widget.NewLabel(i18n.T("gui.history.user"))))),
+		nil, nil, nil,
+		SetlistView.List,
+	)
+}
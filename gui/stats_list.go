@@ -0,0 +1,117 @@
+package gui
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/i18n"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"time"
+)
+
+var statsCategories = []string{"songs", "artists", "requesters", "streams"}
+
+// statsRanges is the time range of stats in days, 0 means all time
+var statsRanges = []int{0, 30, 7, 1}
+
+type StatsContainer struct {
+	Category string
+	Range    int
+	Rows     [][3]string
+	List     *widget.List
+}
+
+var StatsView = &StatsContainer{Category: statsCategories[0]}
+
+func formatPlayTime(seconds int) string {
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+func (s *StatsContainer) Refresh() {
+	since := int64(0)
+	if s.Range > 0 {
+		since = time.Now().AddDate(0, 0, -s.Range).Unix()
+	}
+	rows := make([][3]string, 0)
+	if s.Category == "streams" {
+		for _, st := range controller.Stats.StreamSummaries(since) {
+			rows = append(rows, [3]string{
+				time.Unix(st.Start, 0).Format("2006-01-02 15:04"),
+				fmt.Sprintf(i18n.T("gui.stats.stream.summary"), st.Plays, st.Skipped, st.Requested, st.Requesters),
+				formatPlayTime(st.PlayTime),
+			})
+		}
+	} else {
+		var entries []controller.StatEntry
+		switch s.Category {
+		case "songs":
+			entries = controller.Stats.TopSongs(100, since)
+		case "artists":
+			entries = controller.Stats.TopArtists(100, since)
+		case "requesters":
+			entries = controller.Stats.TopRequesters(100, since)
+		}
+		for _, e := range entries {
+			rows = append(rows, [3]string{e.Name, fmt.Sprintf("%d", e.Count), formatPlayTime(e.PlayTime)})
+		}
+	}
+	s.Rows = rows
+	s.List.Refresh()
+}
+
+func createStatsList() fyne.CanvasObject {
+	StatsView.List = widget.NewList(
+		func() int {
+			return len(StatsView.Rows)
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil,
+				widget.NewLabel("index"), nil,
+				container.NewGridWithColumns(3,
+					newLabelWithWrapping("name", fyne.TextTruncate),
+					newLabelWithWrapping("count", fyne.TextTruncate),
+					newLabelWithWrapping("time", fyne.TextTruncate)))
+		},
+		func(id widget.ListItemID, object fyne.CanvasObject) {
+			row := StatsView.Rows[id]
+			for i := 0; i < 3; i++ {
+				object.(*fyne.Container).Objects[0].(*fyne.Container).Objects[i].(*widget.Label).SetText(row[i])
+			}
+			object.(*fyne.Container).Objects[1].(*widget.Label).SetText(fmt.Sprintf("%d", id+1))
+		})
+	categories := make([]string, len(statsCategories))
+	for i, c := range statsCategories {
+		categories[i] = i18n.T("gui.stats.category." + c)
+	}
+	ranges := make([]string, len(statsRanges))
+	for i, r := range statsRanges {
+		ranges[i] = i18n.T(fmt.Sprintf("gui.stats.range.%d", r))
+	}
+	categorySelect := widget.NewSelect(categories, nil)
+	categorySelect.OnChanged = func(s string) {
+		StatsView.Category = statsCategories[categorySelect.SelectedIndex()]
+		StatsView.Refresh()
+	}
+	rangeSelect := widget.NewSelect(ranges, nil)
+	rangeSelect.OnChanged = func(s string) {
+		StatsView.Range = statsRanges[rangeSelect.SelectedIndex()]
+		StatsView.Refresh()
+	}
+	categorySelect.SetSelectedIndex(0)
+	rangeSelect.SetSelectedIndex(0)
+	return container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(categorySelect, rangeSelect,
+				widget.NewButtonWithIcon(i18n.T("gui.stats.refresh"), theme.ViewRefreshIcon(), StatsView.Refresh)),
+			container.NewBorder(nil, nil,
+				widget.NewLabel("#"), nil,
+				container.NewGridWithColumns(3,
+					widget.NewLabel(i18n.T("gui.stats.name")),
+					widget.NewLabel(i18n.T("gui.stats.count")),
+					widget.NewLabel(i18n.T("gui.stats.play_time"))))),
+		nil, nil, nil,
+		StatsView.List,
+	)
+}
@@ -0,0 +1,42 @@
+package liveclient
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff calculate exponential delay between reconnect attempts
+type Backoff struct {
+	Min    time.Duration
+	Max    time.Duration
+	Factor float64
+	// Jitter is the fraction of delay randomly added or subtracted, from 0 to 1
+	Jitter float64
+}
+
+var DefaultBackoff = Backoff{
+	Min:    time.Second,
+	Max:    time.Minute,
+	Factor: 2,
+	Jitter: 0.2,
+}
+
+// Delay return the delay before attempt (starting from 1), it never exceeds Max
+func (b Backoff) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(b.Min) * math.Pow(b.Factor, float64(attempt-1))
+	if d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	d *= 1 + b.Jitter*(2*rand.Float64()-1)
+	if d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
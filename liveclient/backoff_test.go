@@ -0,0 +1,31 @@
+package liveclient
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Min: time.Second, Max: 10 * time.Second, Factor: 2}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, e := range expected {
+		if d := b.Delay(i + 1); d != e {
+			t.Fatalf("attempt %d: expect %s, got %s", i+1, e, d)
+		}
+	}
+	if b.Delay(1000) != 10*time.Second {
+		t.Fatal("delay should not overflow")
+	}
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := b.Delay(2)
+		if d < time.Second || d > 3*time.Second {
+			t.Fatalf("jitter out of range: %s", d)
+		}
+		if b.Delay(10) > b.Max {
+			t.Fatal("delay with jitter should not exceed max")
+		}
+	}
+	fmt.Println(DefaultBackoff.Delay(1), DefaultBackoff.Delay(5), DefaultBackoff.Delay(100))
+}
@@ -6,24 +6,70 @@ import (
 	"github.com/aynakeya/blivedm"
 	"github.com/sirupsen/logrus"
 	"strconv"
+	"sync"
 	"time"
 )
 
+// live_status in room info, 0 = offline, 1 = live, 2 = playing replay
+const bilibiliLiveStatusLive = 1
+
+// default limits of bilibili chat message for normal users
+const (
+	BilibiliMessageInterval  = 1500 * time.Millisecond
+	BilibiliMessageMaxLength = 20
+)
+
+// bilibiliReinitAttempts is the number of failed reconnect attempts after which
+// room info and danmu server info are fetched again before connecting
+const bilibiliReinitAttempts = 3
+
+// BilibiliAccount is the login session used to send messages,
+// SessData and BiliJct are the cookies SESSDATA and bili_jct.
+type BilibiliAccount struct {
+	Uid      int
+	SessData string
+	BiliJct  string
+}
+
 type Bilibili struct {
 	client   *blivedm.BLiveWsClient
 	handlers *event.Handler
+	sender   *rateLimitedSender
+	backoff  Backoff
+	// stop is closed when disconnected manually, nil if not connected
+	stop         chan struct{}
+	reconnecting bool
+	// roomInitialized is false until room info is fetched, reconnect fetches it again if false
+	roomInitialized bool
+	lock            sync.Mutex
 }
 
 func NewBilibili(roomId int) LiveClient {
+	return NewBilibiliWithAccount(roomId, BilibiliAccount{}, BilibiliMessageInterval, BilibiliMessageMaxLength)
+}
+
+// NewBilibiliWithAccount create a bilibili client which is able to send message with the account,
+// messages are split by maxLength and sent with at least interval between each other.
+func NewBilibiliWithAccount(roomId int, account BilibiliAccount, interval time.Duration, maxLength int) LiveClient {
 	cl := &Bilibili{
-		client:   &blivedm.BLiveWsClient{ShortId: roomId, Account: blivedm.DanmuAccount{UID: 0}, HearbeatInterval: 10 * time.Second},
+		client: &blivedm.BLiveWsClient{
+			ShortId: roomId,
+			Account: blivedm.DanmuAccount{
+				UID:         account.Uid,
+				SessionData: account.SessData,
+				BilibiliJCT: account.BiliJct,
+			},
+			HearbeatInterval: 10 * time.Second,
+		},
 		handlers: event.NewHandler(),
+		backoff:  DefaultBackoff,
 	}
-	cl.client.OnDisconnect = func(client *blivedm.BLiveWsClient) {
-		cl.l().Warn("disconnect from websocket connection, maybe try reconnect")
-		cl.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: false, Client: cl})
-	}
+	cl.sender = newRateLimitedSender(interval, maxLength, cl.sendDanmaku)
+	cl.client.OnDisconnect = cl.onDisconnect
 	cl.client.RegHandler(blivedm.CmdDanmaku, cl.handleMsg)
+	cl.client.RegHandler(blivedm.CmdLive, cl.handleLive)
+	cl.client.RegHandler(blivedm.CmdPreparing, cl.handlePreparing)
+	cl.registerEventHandlers()
 	return cl
 }
 
@@ -36,23 +82,147 @@ func (b *Bilibili) Handler() *event.Handler {
 }
 
 func (b *Bilibili) Connect() bool {
+	b.lock.Lock()
+	stop := make(chan struct{})
+	b.stop = stop
+	// connection lost while connecting is handled below instead of onDisconnect
+	b.reconnecting = true
+	b.lock.Unlock()
 	b.l().Info("Trying Connect Danmu Server")
-	if b.client.InitRoom() && b.client.ConnectDanmuServer() {
-		b.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: true, Client: b})
-		b.l().Info("Connect Success")
+	if b.connect(true, 0) {
+		b.setReconnecting(false)
+		// connection lost before reconnecting flag is cleared, onDisconnect was ignored
+		if !b.client.Running {
+			b.onDisconnect(b.client)
+		}
 		return true
 	}
-	b.l().Info("Connect Failed")
+	b.l().Info("Connect Failed, try reconnect")
+	go b.reconnect(stop)
 	return false
 }
 
+// connect to danmu server, room info is fetched again if initRoom is true.
+// attempt is the number of reconnect attempt, 0 if not reconnecting.
+func (b *Bilibili) connect(initRoom bool, attempt int) bool {
+	if initRoom {
+		if !b.client.InitRoom() {
+			return false
+		}
+		b.roomInitialized = true
+	}
+	if !b.client.ConnectDanmuServer() {
+		return false
+	}
+	b.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: true, Attempt: attempt, Client: b})
+	b.l().Info("Connect Success")
+	if initRoom && b.client.RoomInfo.LiveStatus == bilibiliLiveStatusLive {
+		b.Handler().CallA(EventLiveStatus, LiveStatusEvent{Live: true, StartTime: b.liveStartTime(), Client: b})
+	}
+	return true
+}
+
 func (b *Bilibili) Disconnect() bool {
 	b.l().Info("Disconnect from danmu server")
-	b.client.Disconnect()
+	b.lock.Lock()
+	// close stop first, so the disconnection is not treated as connection lost
+	if b.stop != nil {
+		close(b.stop)
+		b.stop = nil
+	}
+	b.lock.Unlock()
+	if b.client.WsConn != nil {
+		b.client.Disconnect()
+	}
 	b.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: false, Client: b})
 	return true
 }
 
+// onDisconnect is called when websocket connection is closed, it starts reconnecting
+// unless disconnected manually or already reconnecting
+func (b *Bilibili) onDisconnect(client *blivedm.BLiveWsClient) {
+	b.lock.Lock()
+	stop := b.stop
+	if stop == nil || b.reconnecting {
+		b.lock.Unlock()
+		return
+	}
+	b.reconnecting = true
+	b.lock.Unlock()
+	b.l().Warn("disconnect from websocket connection, try reconnect")
+	b.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: false, Client: b})
+	go b.reconnect(stop)
+}
+
+// reconnect until success or stop is closed, waiting longer after each failed attempt
+func (b *Bilibili) reconnect(stop chan struct{}) {
+	for attempt := 1; ; attempt++ {
+		delay := b.backoff.Delay(attempt)
+		b.l().Infof("reconnect attempt %d in %s", attempt, delay)
+		b.Handler().CallA(EventStatusChange, StatusChangeEvent{Reconnecting: true, Attempt: attempt, Client: b})
+		select {
+		case <-stop:
+			b.l().Info("stop reconnecting, disconnected manually")
+			b.setReconnecting(false)
+			return
+		case <-time.After(delay):
+		}
+		// danmu server info or token might be outdated after several failures
+		if !b.connect(!b.roomInitialized || attempt >= bilibiliReinitAttempts, attempt) {
+			b.l().Warnf("reconnect attempt %d failed", attempt)
+			continue
+		}
+		b.l().Infof("reconnect success after %d attempts", attempt)
+		select {
+		case <-stop:
+			// disconnected manually while connecting
+			b.client.Disconnect()
+			b.setReconnecting(false)
+			return
+		default:
+		}
+		b.setReconnecting(false)
+		// connection lost again before reconnecting flag is cleared, onDisconnect was ignored
+		if !b.client.Running {
+			b.onDisconnect(b.client)
+		}
+		return
+	}
+}
+
+func (b *Bilibili) setReconnecting(reconnecting bool) {
+	b.lock.Lock()
+	b.reconnecting = reconnecting
+	b.lock.Unlock()
+}
+
+func (b *Bilibili) SendMessage(message string) error {
+	if b.client.Account.SessionData == "" || b.client.Account.BilibiliJCT == "" {
+		return ErrorNotLoggedIn
+	}
+	return b.sender.SendMessage(message)
+}
+
+func (b *Bilibili) sendDanmaku(message string) error {
+	resp, err := b.client.SendMessage(blivedm.DanmakuSendForm{
+		Message:  message,
+		Color:    "16777215",
+		Mode:     1,
+		Fontsize: 25,
+		Rnd:      int(time.Now().Unix()),
+	})
+	if err != nil {
+		b.l().Warnf("send message %s failed: %s", message, err)
+		return err
+	}
+	if resp.Code != 0 {
+		b.l().Warnf("send message %s failed: %d %s", message, resp.Code, resp.Message)
+		return ErrorSendFailed
+	}
+	b.l().Debugf("send message %s", message)
+	return nil
+}
+
 func (b *Bilibili) l() *logrus.Entry {
 	return logger.Logger.WithFields(logrus.Fields{
 		"Module":     MODULE_NAME,
@@ -89,3 +259,23 @@ func (b *Bilibili) handleMsg(context *blivedm.Context) {
 		})
 	}()
 }
+
+// liveStartTime return the start time of current stream in room info, or now if unknown
+func (b *Bilibili) liveStartTime() time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", b.client.RoomInfo.LiveTime, time.Local)
+	if err != nil || t.Year() < 2000 {
+		b.l().Warnf("invalid live time %s, use current time as stream start", b.client.RoomInfo.LiveTime)
+		return time.Now()
+	}
+	return t
+}
+
+func (b *Bilibili) handleLive(context *blivedm.Context) {
+	b.l().Info("stream started")
+	b.handlers.CallA(EventLiveStatus, LiveStatusEvent{Live: true, StartTime: time.Now(), Client: b})
+}
+
+func (b *Bilibili) handlePreparing(context *blivedm.Context) {
+	b.l().Info("stream ended")
+	b.handlers.CallA(EventLiveStatus, LiveStatusEvent{Live: false, Client: b})
+}
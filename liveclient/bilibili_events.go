@@ -0,0 +1,121 @@
+package liveclient
+
+import (
+	"AynaLivePlayer/event"
+	"github.com/aynakeya/blivedm"
+	"github.com/tidwall/gjson"
+	"strconv"
+)
+
+// commands not defined in blivedm
+const (
+	bilibiliCmdSuperChat = "SUPER_CHAT_MESSAGE"
+	bilibiliCmdGuardBuy  = "GUARD_BUY"
+	bilibiliCmdInteract  = "INTERACT_WORD"
+)
+
+// msg_type of INTERACT_WORD
+const (
+	bilibiliInteractEnter  = 1
+	bilibiliInteractFollow = 2
+)
+
+// 1000 gold coins = 1 CNY
+const bilibiliGoldPerYuan = 1000
+
+func (b *Bilibili) registerEventHandlers() {
+	b.client.RegHandler(blivedm.CmdSendGift, b.handleGift)
+	b.client.RegHandler(bilibiliCmdSuperChat, b.handleSuperChat)
+	b.client.RegHandler(bilibiliCmdGuardBuy, b.handleGuardBuy)
+	b.client.RegHandler(bilibiliCmdInteract, b.handleInteract)
+}
+
+func (b *Bilibili) handleGift(context *blivedm.Context) {
+	msg := parseBilibiliGift(context.JsonData.Get("data"))
+	b.l().Debugf("receive gift %s x%d from %s", msg.GiftName, msg.Count, msg.User.Username)
+	b.handlers.CallA(EventGiftReceive, msg)
+}
+
+func (b *Bilibili) handleSuperChat(context *blivedm.Context) {
+	msg := parseBilibiliSuperChat(context.JsonData.Get("data"))
+	b.l().Debugf("receive superchat %.0f from %s: %s", msg.Value, msg.User.Username, msg.Message)
+	b.handlers.CallA(EventSuperChat, msg)
+}
+
+func (b *Bilibili) handleGuardBuy(context *blivedm.Context) {
+	msg := parseBilibiliGuard(context.JsonData.Get("data"))
+	b.l().Debugf("%s buy guard %s x%d", msg.User.Username, msg.Name, msg.Count)
+	b.handlers.CallA(EventGuardBuy, msg)
+}
+
+func (b *Bilibili) handleInteract(context *blivedm.Context) {
+	data := context.JsonData.Get("data")
+	var id event.EventId
+	switch data.Get("msg_type").Int() {
+	case bilibiliInteractEnter:
+		id = EventUserEnter
+	case bilibiliInteractFollow:
+		id = EventUserFollow
+	default:
+		return
+	}
+	b.handlers.CallA(id, &UserMessage{User: parseBilibiliUser(data, "uname", "fans_medal")})
+}
+
+// parseBilibiliUser read user info from data, the name of username field and medal object are different in commands.
+func parseBilibiliUser(data gjson.Result, nameField string, medalField string) DanmuUser {
+	return DanmuUser{
+		Uid:      strconv.FormatInt(data.Get("uid").Int(), 10),
+		Username: data.Get(nameField).String(),
+		Medal: UserMedal{
+			Name:  data.Get(medalField + ".medal_name").String(),
+			Level: int(data.Get(medalField + ".medal_level").Int()),
+		},
+		Privilege: int(data.Get(medalField + ".guard_level").Int()),
+	}
+}
+
+func parseBilibiliGift(data gjson.Result) *GiftMessage {
+	msg := &GiftMessage{
+		User:     parseBilibiliUser(data, "uname", "medal_info"),
+		GiftId:   int(data.Get("giftId").Int()),
+		GiftName: data.Get("giftName").String(),
+		Count:    int(data.Get("num").Int()),
+		Currency: CurrencyFree,
+	}
+	if guard := data.Get("guard_level").Int(); guard > 0 {
+		msg.User.Privilege = int(guard)
+	}
+	if data.Get("coin_type").String() == "gold" {
+		msg.Currency = CurrencyCNY
+		msg.Value = float64(data.Get("price").Int()*data.Get("num").Int()) / bilibiliGoldPerYuan
+	}
+	return msg
+}
+
+func parseBilibiliSuperChat(data gjson.Result) *SuperChatMessage {
+	msg := &SuperChatMessage{
+		User:     parseBilibiliUser(data, "user_info.uname", "medal_info"),
+		Message:  data.Get("message").String(),
+		Value:    data.Get("price").Float(),
+		Currency: CurrencyCNY,
+		Duration: int(data.Get("time").Int()),
+	}
+	if guard := data.Get("user_info.guard_level").Int(); guard > 0 {
+		msg.User.Privilege = int(guard)
+	}
+	return msg
+}
+
+func parseBilibiliGuard(data gjson.Result) *GuardMessage {
+	level := int(data.Get("guard_level").Int())
+	msg := &GuardMessage{
+		User:     DanmuUser{Uid: strconv.FormatInt(data.Get("uid").Int(), 10), Username: data.Get("username").String(), Privilege: level},
+		Level:    level,
+		Name:     data.Get("gift_name").String(),
+		Count:    int(data.Get("num").Int()),
+		Currency: CurrencyCNY,
+	}
+	msg.Value = float64(data.Get("price").Int()*data.Get("num").Int()) / bilibiliGoldPerYuan
+	return msg
+}
@@ -5,6 +5,7 @@ import (
 	"AynaLivePlayer/logger"
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
 	"testing"
 	"time"
 )
@@ -24,3 +25,24 @@ func TestBilibili_Client(t *testing.T) {
 	time.Sleep(time.Second * 60)
 	lc.Disconnect()
 }
+
+func TestBilibili_ParseEvents(t *testing.T) {
+	gift := parseBilibiliGift(gjson.Parse(`{"uid":123,"uname":"user","giftId":31036,"giftName":"小花花","num":5,"price":100,"coin_type":"gold","guard_level":3,"medal_info":{"medal_name":"medal","medal_level":12}}`))
+	fmt.Println(gift)
+	if gift.Value != 0.5 || gift.Currency != CurrencyCNY || gift.User.Privilege != 3 || gift.User.Medal.Level != 12 {
+		t.Fatal("gold gift is not parsed correctly")
+	}
+	if parseBilibiliGift(gjson.Parse(`{"uid":123,"num":1,"price":100,"coin_type":"silver"}`)).Value != 0 {
+		t.Fatal("silver gift should have no value")
+	}
+	sc := parseBilibiliSuperChat(gjson.Parse(`{"uid":123,"price":30,"message":"晴天","time":60,"user_info":{"uname":"user","guard_level":0},"medal_info":{"medal_name":"medal","medal_level":3}}`))
+	fmt.Println(sc)
+	if sc.Value != 30 || sc.User.Username != "user" || sc.Message != "晴天" {
+		t.Fatal("superchat is not parsed correctly")
+	}
+	guard := parseBilibiliGuard(gjson.Parse(`{"uid":123,"username":"user","guard_level":3,"num":2,"price":198000,"gift_name":"舰长"}`))
+	fmt.Println(guard)
+	if guard.Value != 396 || guard.User.Privilege != 3 {
+		t.Fatal("guard is not parsed correctly")
+	}
+}
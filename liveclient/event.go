@@ -2,14 +2,35 @@ package liveclient
 
 import (
 	"AynaLivePlayer/event"
+	"time"
 )
 
 const (
 	EventStatusChange   event.EventId = "liveclient.status.change"
 	EventMessageReceive event.EventId = "liveclient.message.receive"
+	EventLiveStatus     event.EventId = "liveclient.live.status"
+	EventGiftReceive    event.EventId = "liveclient.gift.receive"
+	EventSuperChat      event.EventId = "liveclient.superchat.receive"
+	EventGuardBuy       event.EventId = "liveclient.guard.buy"
+	EventUserEnter      event.EventId = "liveclient.user.enter"
+	EventUserFollow     event.EventId = "liveclient.user.follow"
 )
 
+// StatusChangeEvent is sent when connection status changes. Reconnecting is true
+// before each reconnect attempt, and Attempt is the number of current attempt.
+// Error is set if connection failed and will not be retried.
 type StatusChangeEvent struct {
-	Connected bool
+	Connected    bool
+	Reconnecting bool
+	Attempt      int
+	Error        error
+	Client       LiveClient
+}
+
+// LiveStatusEvent is sent when the stream starts or ends,
+// StartTime is zero if the stream is not live.
+type LiveStatusEvent struct {
+	Live      bool
+	StartTime time.Time
 	Client    LiveClient
 }
@@ -0,0 +1,178 @@
+package liveclient
+
+import (
+	"bufio"
+	"crypto/tls"
+	"github.com/gorilla/websocket"
+	"net"
+	"strings"
+	"time"
+)
+
+const ircDialTimeout = 10 * time.Second
+
+// ircMessage is a parsed irc line with IRCv3 tags, trailing parameter is the last one in Params
+type ircMessage struct {
+	Tags    map[string]string
+	Prefix  string
+	Command string
+	Params  []string
+}
+
+var ircTagEscaper = strings.NewReplacer(`\:`, ";", `\s`, " ", `\\`, `\`, `\r`, "\r", `\n`, "\n")
+
+// parseIRCMessage parse a line like "@tag=value :nick!user@host COMMAND param :trailing"
+func parseIRCMessage(line string) (*ircMessage, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	msg := &ircMessage{Tags: make(map[string]string)}
+	if strings.HasPrefix(line, "@") {
+		idx := strings.IndexByte(line, ' ')
+		if idx < 0 {
+			return nil, false
+		}
+		for _, tag := range strings.Split(line[1:idx], ";") {
+			kv := strings.SplitN(tag, "=", 2)
+			if len(kv) == 2 {
+				msg.Tags[kv[0]] = ircTagEscaper.Replace(kv[1])
+			} else {
+				msg.Tags[kv[0]] = ""
+			}
+		}
+		line = strings.TrimLeft(line[idx+1:], " ")
+	}
+	if strings.HasPrefix(line, ":") {
+		idx := strings.IndexByte(line, ' ')
+		if idx < 0 {
+			return nil, false
+		}
+		msg.Prefix = line[1:idx]
+		line = strings.TrimLeft(line[idx+1:], " ")
+	}
+	trailing := ""
+	hasTrailing := false
+	if idx := strings.Index(line, " :"); idx >= 0 {
+		trailing = line[idx+2:]
+		hasTrailing = true
+		line = line[:idx]
+	} else if strings.HasPrefix(line, ":") {
+		return nil, false
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, false
+	}
+	msg.Command = strings.ToUpper(fields[0])
+	msg.Params = fields[1:]
+	if hasTrailing {
+		msg.Params = append(msg.Params, trailing)
+	}
+	return msg, true
+}
+
+// Nick return nickname in prefix
+func (m *ircMessage) Nick() string {
+	if idx := strings.IndexByte(m.Prefix, '!'); idx >= 0 {
+		return m.Prefix[:idx]
+	}
+	return m.Prefix
+}
+
+// Trailing return the last parameter, empty if there is no parameter
+func (m *ircMessage) Trailing() string {
+	if len(m.Params) == 0 {
+		return ""
+	}
+	return m.Params[len(m.Params)-1]
+}
+
+// ircConn is a line based connection to irc server
+type ircConn interface {
+	ReadLine() (string, error)
+	WriteLine(line string) error
+	SetReadDeadline(t time.Time) error
+	Close() error
+}
+
+// dialIRC connect to irc server, address starting with ws:// or wss:// is connected with websocket,
+// address starting with tls:// is connected with tls, otherwise plain tcp is used.
+func dialIRC(address string) (ircConn, error) {
+	if strings.HasPrefix(address, "ws://") || strings.HasPrefix(address, "wss://") {
+		dialer := *websocket.DefaultDialer
+		dialer.HandshakeTimeout = ircDialTimeout
+		conn, _, err := dialer.Dial(address, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &wsIRCConn{conn: conn}, nil
+	}
+	dialer := &net.Dialer{Timeout: ircDialTimeout}
+	if strings.HasPrefix(address, "tls://") {
+		conn, err := tls.DialWithDialer(dialer, "tcp", strings.TrimPrefix(address, "tls://"), nil)
+		if err != nil {
+			return nil, err
+		}
+		return newTcpIRCConn(conn), nil
+	}
+	conn, err := dialer.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	return newTcpIRCConn(conn), nil
+}
+
+type tcpIRCConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func newTcpIRCConn(conn net.Conn) *tcpIRCConn {
+	return &tcpIRCConn{Conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *tcpIRCConn) ReadLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (c *tcpIRCConn) WriteLine(line string) error {
+	_, err := c.Write([]byte(line + "\r\n"))
+	return err
+}
+
+// wsIRCConn is irc over websocket, one websocket message might contain multiple lines
+type wsIRCConn struct {
+	conn  *websocket.Conn
+	lines []string
+}
+
+func (c *wsIRCConn) ReadLine() (string, error) {
+	for len(c.lines) == 0 {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return "", err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimRight(line, "\r"); line != "" {
+				c.lines = append(c.lines, line)
+			}
+		}
+	}
+	line := c.lines[0]
+	c.lines = c.lines[1:]
+	return line, nil
+}
+
+func (c *wsIRCConn) WriteLine(line string) error {
+	return c.conn.WriteMessage(websocket.TextMessage, []byte(line+"\r\n"))
+}
+
+func (c *wsIRCConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *wsIRCConn) Close() error {
+	return c.conn.Close()
+}
@@ -1,6 +1,9 @@
 package liveclient
 
-import "AynaLivePlayer/event"
+import (
+	"AynaLivePlayer/event"
+	"errors"
+)
 
 const MODULE_NAME = "LiveClient"
 
@@ -22,9 +25,66 @@ type DanmuMessage struct {
 	Message string
 }
 
+// Currency of paid messages, Value in messages is in unit of the currency
+type Currency string
+
+const (
+	CurrencyCNY Currency = "CNY"
+	// CurrencyFree is used for gifts which have no real value, like bilibili silver gifts
+	CurrencyFree Currency = "free"
+)
+
+type GiftMessage struct {
+	User     DanmuUser
+	GiftId   int
+	GiftName string
+	Count    int
+	// Value is the total value of all gifts
+	Value    float64
+	Currency Currency
+}
+
+type SuperChatMessage struct {
+	User     DanmuUser
+	Message  string
+	Value    float64
+	Currency Currency
+	// Duration is how long the SuperChat is pinned, in seconds
+	Duration int
+}
+
+type GuardMessage struct {
+	User DanmuUser
+	// Level is the guard level, same as DanmuUser.Privilege
+	Level int
+	Name  string
+	// Count is number of months
+	Count    int
+	Value    float64
+	Currency Currency
+}
+
+// UserMessage is used for events without content, like entering room and following
+type UserMessage struct {
+	User DanmuUser
+}
+
 type LiveClient interface {
 	ClientName() string
 	Connect() bool
 	Disconnect() bool
 	Handler() *event.Handler
 }
+
+var (
+	ErrorNotLoggedIn  = errors.New("live client is not logged in")
+	ErrorSendFailed   = errors.New("send message failed")
+	ErrorEmptyMessage = errors.New("message is empty")
+	ErrorLoginFailed  = errors.New("live client login failed")
+)
+
+// MessageSender is an optional capability of LiveClient, for clients which
+// can send chat messages to the live room.
+type MessageSender interface {
+	SendMessage(message string) error
+}
@@ -0,0 +1,50 @@
+package liveclient
+
+import (
+	"AynaLivePlayer/util"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxMessageParts is the maximum number of parts a message is split into, the rest is dropped
+// so a long message won't flood the chat.
+const maxMessageParts = 3
+
+// rateLimitedSender split long message and make sure there is an interval between messages,
+// SendMessage blocks until all parts of the message are sent.
+type rateLimitedSender struct {
+	Interval  time.Duration
+	MaxLength int
+	send      func(message string) error
+	lastSent  time.Time
+	lock      sync.Mutex
+}
+
+func newRateLimitedSender(interval time.Duration, maxLength int, send func(message string) error) *rateLimitedSender {
+	return &rateLimitedSender{Interval: interval, MaxLength: maxLength, send: send}
+}
+
+func (s *rateLimitedSender) SendMessage(message string) error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return ErrorEmptyMessage
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	parts := util.SplitByLength(message, s.MaxLength)
+	if len(parts) > maxMessageParts {
+		parts = parts[:maxMessageParts]
+	}
+	for _, part := range parts {
+		if wait := s.Interval - time.Since(s.lastSent); wait > 0 {
+			time.Sleep(wait)
+		}
+		err := s.send(part)
+		s.lastSent = time.Now()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
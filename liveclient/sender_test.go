@@ -0,0 +1,38 @@
+package liveclient
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedSender_SendMessage(t *testing.T) {
+	var sent []time.Time
+	s := newRateLimitedSender(100*time.Millisecond, 5, func(message string) error {
+		fmt.Println(message)
+		sent = append(sent, time.Now())
+		return nil
+	})
+	if err := s.SendMessage("一二三四五六七八九十十一"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 3 {
+		t.Fatal("message should be split into 3 parts")
+	}
+	for i := 1; i < len(sent); i++ {
+		if sent[i].Sub(sent[i-1]) < 100*time.Millisecond {
+			t.Fatal("messages should be sent with interval")
+		}
+	}
+	if s.SendMessage("  ") != ErrorEmptyMessage {
+		t.Fatal("empty message should not be sent")
+	}
+	sent = nil
+	s.Interval = 0
+	if err := s.SendMessage("一二三四五六七八九十十一十二十三十四十五十六"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != maxMessageParts {
+		t.Fatal("long message should be truncated")
+	}
+}
@@ -0,0 +1,388 @@
+package liveclient
+
+import (
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/logger"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TwitchWebsocketAddress = "wss://irc-ws.chat.twitch.tv:443"
+	TwitchTcpAddress       = "tls://irc.chat.twitch.tv:6697"
+)
+
+// TwitchUidPrefix is prepended to twitch user id, so it does not collide with bilibili uid in stores
+const TwitchUidPrefix = "twitch:"
+
+// default limits of twitch chat message for normal users
+const (
+	TwitchMessageInterval  = 1500 * time.Millisecond
+	TwitchMessageMaxLength = 500
+)
+
+// privilege of twitch users, lower is higher like bilibili guard level
+const (
+	twitchPrivilegeVIP        = 2
+	twitchPrivilegeSubscriber = 3
+)
+
+const twitchLoginTimeout = 10 * time.Second
+
+var errorTwitchStopped = errors.New("disconnected while connecting")
+
+// TwitchAccount is the account used to login, Token is the oauth token with chat scopes.
+// Login is anonymous and read only if Username or Token is empty.
+type TwitchAccount struct {
+	Username string
+	Token    string
+}
+
+func (a TwitchAccount) Anonymous() bool {
+	return a.Username == "" || a.Token == ""
+}
+
+type Twitch struct {
+	// Address of twitch irc server, see dialIRC for supported formats
+	Address string
+	Channel string
+	account TwitchAccount
+	conn    ircConn
+	// stop is closed when disconnected manually, nil if not connected
+	stop      chan struct{}
+	handlers  *event.Handler
+	sender    *rateLimitedSender
+	backoff   Backoff
+	lock      sync.Mutex
+	writeLock sync.Mutex
+}
+
+func NewTwitch(channel string) LiveClient {
+	return NewTwitchWithAccount(channel, TwitchAccount{}, TwitchMessageInterval, TwitchMessageMaxLength)
+}
+
+// NewTwitchWithAccount create a twitch client which is able to send message with the account,
+// messages are split by maxLength and sent with at least interval between each other.
+func NewTwitchWithAccount(channel string, account TwitchAccount, interval time.Duration, maxLength int) LiveClient {
+	cl := &Twitch{
+		Address:  TwitchWebsocketAddress,
+		Channel:  strings.ToLower(strings.TrimPrefix(strings.TrimSpace(channel), "#")),
+		account:  account,
+		handlers: event.NewHandler(),
+		backoff:  DefaultBackoff,
+	}
+	cl.sender = newRateLimitedSender(interval, maxLength, cl.sendPrivmsg)
+	return cl
+}
+
+func (t *Twitch) ClientName() string {
+	return "twitch"
+}
+
+func (t *Twitch) Handler() *event.Handler {
+	return t.handlers
+}
+
+func (t *Twitch) Connect() bool {
+	t.lock.Lock()
+	stop := make(chan struct{})
+	t.stop = stop
+	t.lock.Unlock()
+	t.l().Info("Trying Connect Twitch Chat")
+	err := t.connect(0)
+	if err == nil {
+		return true
+	}
+	if err == ErrorLoginFailed {
+		t.onLoginFailed(stop)
+		return false
+	}
+	t.l().Info("Connect Failed, try reconnect")
+	go t.reconnect(stop)
+	return false
+}
+
+// connect to irc server and join the channel, attempt is the number of reconnect attempt,
+// 0 if not reconnecting. ErrorLoginFailed is returned if the account is rejected.
+func (t *Twitch) connect(attempt int) error {
+	conn, err := dialIRC(t.Address)
+	if err != nil {
+		t.l().Warnf("connect to %s failed: %s", t.Address, err)
+		return err
+	}
+	if err = t.login(conn); err != nil {
+		t.l().Warnf("login failed: %s", err)
+		_ = conn.Close()
+		return err
+	}
+	t.lock.Lock()
+	if t.stop == nil {
+		// disconnected manually while connecting
+		t.lock.Unlock()
+		_ = conn.Close()
+		return errorTwitchStopped
+	}
+	t.conn = conn
+	t.lock.Unlock()
+	go t.readLoop(conn)
+	t.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: true, Attempt: attempt, Client: t})
+	t.l().Info("Connect Success")
+	return nil
+}
+
+// onLoginFailed stop connecting, the account is rejected so retrying would never succeed
+func (t *Twitch) onLoginFailed(stop chan struct{}) {
+	t.lock.Lock()
+	if t.stop == stop {
+		close(t.stop)
+		t.stop = nil
+	}
+	t.lock.Unlock()
+	t.l().Warn("login rejected, check username and token")
+	t.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: false, Error: ErrorLoginFailed, Client: t})
+}
+
+// login request tags and commands capabilities, then join the channel after welcome message
+func (t *Twitch) login(conn ircConn) error {
+	nick := "justinfan" + strconv.Itoa(10000+rand.Intn(90000))
+	lines := []string{"CAP REQ :twitch.tv/tags twitch.tv/commands"}
+	if !t.account.Anonymous() {
+		nick = strings.ToLower(t.account.Username)
+		lines = append(lines, "PASS oauth:"+strings.TrimPrefix(t.account.Token, "oauth:"))
+	}
+	lines = append(lines, "NICK "+nick)
+	for _, line := range lines {
+		if err := t.write(conn, line); err != nil {
+			return err
+		}
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(twitchLoginTimeout))
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return err
+		}
+		msg, ok := parseIRCMessage(line)
+		if !ok {
+			continue
+		}
+		switch msg.Command {
+		case "001":
+			_ = conn.SetReadDeadline(time.Time{})
+			return t.write(conn, "JOIN #"+t.Channel)
+		case "PING":
+			_ = t.write(conn, "PONG :"+msg.Trailing())
+		case "NOTICE":
+			// twitch only sends notice to * before welcome when authentication failed
+			t.l().Warnf("login notice: %s", msg.Trailing())
+			return ErrorLoginFailed
+		}
+	}
+}
+
+func (t *Twitch) Disconnect() bool {
+	t.l().Info("Disconnect from twitch chat")
+	t.lock.Lock()
+	// close stop first, so the disconnection is not treated as connection lost
+	if t.stop != nil {
+		close(t.stop)
+		t.stop = nil
+	}
+	conn := t.conn
+	t.conn = nil
+	t.lock.Unlock()
+	if conn != nil {
+		_ = conn.Close()
+	}
+	t.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: false, Client: t})
+	return true
+}
+
+func (t *Twitch) readLoop(conn ircConn) {
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			t.l().Debugf("read from connection failed: %s", err)
+			break
+		}
+		if msg, ok := parseIRCMessage(line); ok {
+			t.handleIRCMessage(conn, msg)
+		}
+	}
+	t.onDisconnect(conn)
+}
+
+// onDisconnect start reconnecting if conn is the current connection and not disconnected manually
+func (t *Twitch) onDisconnect(conn ircConn) {
+	t.lock.Lock()
+	stop := t.stop
+	if stop == nil || t.conn != conn {
+		t.lock.Unlock()
+		return
+	}
+	t.conn = nil
+	t.lock.Unlock()
+	_ = conn.Close()
+	t.l().Warn("disconnect from twitch chat, try reconnect")
+	t.Handler().CallA(EventStatusChange, StatusChangeEvent{Connected: false, Client: t})
+	go t.reconnect(stop)
+}
+
+// reconnect until success or stop is closed, waiting longer after each failed attempt.
+// it stops if login is rejected.
+func (t *Twitch) reconnect(stop chan struct{}) {
+	for attempt := 1; ; attempt++ {
+		delay := t.backoff.Delay(attempt)
+		t.l().Infof("reconnect attempt %d in %s", attempt, delay)
+		t.Handler().CallA(EventStatusChange, StatusChangeEvent{Reconnecting: true, Attempt: attempt, Client: t})
+		select {
+		case <-stop:
+			t.l().Info("stop reconnecting, disconnected manually")
+			return
+		case <-time.After(delay):
+		}
+		err := t.connect(attempt)
+		if err == nil {
+			t.l().Infof("reconnect success after %d attempts", attempt)
+			return
+		}
+		if err == ErrorLoginFailed {
+			t.onLoginFailed(stop)
+			return
+		}
+		t.l().Warnf("reconnect attempt %d failed", attempt)
+	}
+}
+
+func (t *Twitch) handleIRCMessage(conn ircConn, msg *ircMessage) {
+	switch msg.Command {
+	case "PING":
+		if err := t.write(conn, "PONG :"+msg.Trailing()); err != nil {
+			t.l().Warnf("send pong failed: %s", err)
+		}
+	case "PRIVMSG":
+		t.handleMsg(msg)
+	case "RECONNECT":
+		// server is going to restart, close connection so it reconnects
+		t.l().Info("server request reconnect")
+		_ = conn.Close()
+	case "NOTICE":
+		t.l().Infof("notice: %s", msg.Trailing())
+	}
+}
+
+func (t *Twitch) handleMsg(msg *ircMessage) {
+	if len(msg.Params) < 2 {
+		return
+	}
+	text := msg.Trailing()
+	// messages sent with /me
+	if strings.HasPrefix(text, "\x01ACTION ") && strings.HasSuffix(text, "\x01") {
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "\x01ACTION "), "\x01")
+	}
+	dmsg := DanmuMessage{
+		User:    parseTwitchUser(msg, t.Channel),
+		Message: text,
+	}
+	t.l().Debug("receive message", dmsg)
+	go func() {
+		t.handlers.Call(&event.Event{
+			Id:        EventMessageReceive,
+			Cancelled: false,
+			Data:      &dmsg,
+		})
+	}()
+}
+
+// parseTwitchUser map badges onto DanmuUser, broadcaster and moderators are admin,
+// subscribers have a medal of the channel with subscribed months as level.
+// Uid is user id, or nickname if there is no id, prefixed with TwitchUidPrefix.
+func parseTwitchUser(msg *ircMessage, channel string) DanmuUser {
+	user := DanmuUser{
+		Uid:      TwitchUidPrefix + msg.Tags["user-id"],
+		Username: msg.Tags["display-name"],
+	}
+	if msg.Tags["user-id"] == "" {
+		user.Uid = TwitchUidPrefix + msg.Nick()
+	}
+	if user.Username == "" {
+		user.Username = msg.Nick()
+	}
+	badges := parseTwitchBadges(msg.Tags["badges"])
+	_, broadcaster := badges["broadcaster"]
+	_, moderator := badges["moderator"]
+	user.Admin = broadcaster || moderator || msg.Tags["mod"] == "1"
+	if _, ok := badges["subscriber"]; ok || msg.Tags["subscriber"] == "1" {
+		user.Privilege = twitchPrivilegeSubscriber
+		months, err := strconv.Atoi(parseTwitchBadges(msg.Tags["badge-info"])["subscriber"])
+		if err != nil || months < 1 {
+			months = 1
+		}
+		user.Medal = UserMedal{Name: channel, Level: months}
+	}
+	if _, ok := badges["vip"]; ok || msg.Tags["vip"] == "1" {
+		user.Privilege = twitchPrivilegeVIP
+	}
+	return user
+}
+
+// parseTwitchBadges parse badges like "moderator/1,subscriber/12" into map of name to version
+func parseTwitchBadges(badges string) map[string]string {
+	result := make(map[string]string)
+	for _, badge := range strings.Split(badges, ",") {
+		kv := strings.SplitN(badge, "/", 2)
+		if kv[0] == "" {
+			continue
+		}
+		if len(kv) == 2 {
+			result[kv[0]] = kv[1]
+		} else {
+			result[kv[0]] = ""
+		}
+	}
+	return result
+}
+
+func (t *Twitch) SendMessage(message string) error {
+	if t.account.Anonymous() {
+		return ErrorNotLoggedIn
+	}
+	return t.sender.SendMessage(message)
+}
+
+func (t *Twitch) sendPrivmsg(message string) error {
+	t.lock.Lock()
+	conn := t.conn
+	t.lock.Unlock()
+	if conn == nil {
+		return ErrorSendFailed
+	}
+	// line breaks would start a new irc command
+	message = strings.NewReplacer("\r", " ", "\n", " ").Replace(message)
+	if err := t.write(conn, fmt.Sprintf("PRIVMSG #%s :%s", t.Channel, message)); err != nil {
+		t.l().Warnf("send message %s failed: %s", message, err)
+		return err
+	}
+	t.l().Debugf("send message %s", message)
+	return nil
+}
+
+func (t *Twitch) write(conn ircConn, line string) error {
+	t.writeLock.Lock()
+	defer t.writeLock.Unlock()
+	return conn.WriteLine(line)
+}
+
+func (t *Twitch) l() *logrus.Entry {
+	return logger.Logger.WithFields(logrus.Fields{
+		"Module":     MODULE_NAME,
+		"ClientName": t.ClientName(),
+		"Channel":    t.Channel,
+	})
+}
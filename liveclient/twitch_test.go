@@ -0,0 +1,315 @@
+package liveclient
+
+import (
+	"AynaLivePlayer/event"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const twitchTestTimeout = 3 * time.Second
+
+// startIRCServer start a local irc stand-in server, handle is called for each connection
+func startIRCServer(t *testing.T, handle func(conn *tcpIRCConn)) (string, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(newTcpIRCConn(conn))
+			}()
+		}
+	}()
+	return listener.Addr().String(), func() { _ = listener.Close() }
+}
+
+// expectLine read lines until one starts with prefix, return empty if connection is closed
+func expectLine(conn ircConn, prefix string) string {
+	_ = conn.SetReadDeadline(time.Now().Add(twitchTestTimeout))
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return ""
+		}
+		if strings.HasPrefix(line, prefix) {
+			return line
+		}
+	}
+}
+
+func newTestTwitch(address string, account TwitchAccount) (*Twitch, chan *DanmuMessage) {
+	tw := NewTwitchWithAccount("#Channel", account, 0, TwitchMessageMaxLength).(*Twitch)
+	tw.Address = address
+	tw.backoff = Backoff{Min: 10 * time.Millisecond, Max: 50 * time.Millisecond, Factor: 2}
+	messages := make(chan *DanmuMessage, 10)
+	tw.Handler().RegisterA(EventMessageReceive, "test.twitch.message", func(event *event.Event) {
+		messages <- event.Data.(*DanmuMessage)
+	})
+	return tw, messages
+}
+
+func waitMessage(t *testing.T, messages chan *DanmuMessage) *DanmuMessage {
+	select {
+	case msg := <-messages:
+		fmt.Println(msg)
+		return msg
+	case <-time.After(twitchTestTimeout):
+		t.Fatal("message not received")
+	}
+	return nil
+}
+
+func TestParseIRCMessage(t *testing.T) {
+	msg, ok := parseIRCMessage(`@badges=vip/1;display-name=A\sB\:C;emotes= :abc!abc@abc.tmi.twitch.tv PRIVMSG #channel :hello :) world` + "\r\n")
+	if !ok {
+		t.Fatal("message should be parsed")
+	}
+	fmt.Println(msg)
+	if msg.Command != "PRIVMSG" || msg.Nick() != "abc" || len(msg.Params) != 2 ||
+		msg.Params[0] != "#channel" || msg.Trailing() != "hello :) world" {
+		t.Fatal("message is not parsed correctly")
+	}
+	if msg.Tags["display-name"] != "A B;C" || msg.Tags["emotes"] != "" {
+		t.Fatal("tags are not unescaped")
+	}
+	if msg, ok = parseIRCMessage("PING :tmi.twitch.tv"); !ok || msg.Command != "PING" || msg.Trailing() != "tmi.twitch.tv" {
+		t.Fatal("ping is not parsed correctly")
+	}
+	if _, ok = parseIRCMessage(":prefix.only"); ok {
+		t.Fatal("message without command should be invalid")
+	}
+}
+
+func TestParseTwitchUser(t *testing.T) {
+	msg, _ := parseIRCMessage("@badges=vip/1,subscriber/3012;user-id=1 :abc!abc@abc PRIVMSG #channel :hi")
+	user := parseTwitchUser(msg, "channel")
+	fmt.Println(user)
+	if user.Username != "abc" || user.Uid != "twitch:1" || user.Admin ||
+		user.Privilege != twitchPrivilegeVIP || user.Medal.Name != "channel" || user.Medal.Level != 1 {
+		t.Fatal("vip subscriber is not parsed correctly")
+	}
+	msg, _ = parseIRCMessage("@badges=broadcaster/1;display-name=Owner :owner!owner@owner PRIVMSG #channel :hi")
+	user = parseTwitchUser(msg, "channel")
+	if !user.Admin || user.Privilege != 0 || user.Uid != "twitch:owner" || user.Username != "Owner" {
+		t.Fatal("broadcaster should be admin")
+	}
+}
+
+func TestTwitch_Anonymous(t *testing.T) {
+	pong := make(chan string, 1)
+	address, closeServer := startIRCServer(t, func(conn *tcpIRCConn) {
+		if line := expectLine(conn, "NICK "); !strings.HasPrefix(line, "NICK justinfan") {
+			t.Errorf("anonymous login expected, got %s", line)
+			return
+		}
+		_ = conn.WriteLine(":tmi.twitch.tv 001 justinfan :Welcome, GLHF!")
+		if expectLine(conn, "JOIN ") != "JOIN #channel" {
+			t.Error("channel is not joined")
+			return
+		}
+		_ = conn.WriteLine(`@badge-info=subscriber/14;badges=moderator/1,subscriber/12;display-name=Viewer\sOne;user-id=42 :viewer!viewer@viewer.tmi.twitch.tv PRIVMSG #channel :点歌 晴天`)
+		_ = conn.WriteLine("PING :tmi.twitch.tv")
+		pong <- expectLine(conn, "PONG")
+		expectLine(conn, "QUIT")
+	})
+	defer closeServer()
+	tw, messages := newTestTwitch(address, TwitchAccount{})
+	if !tw.Connect() {
+		t.Fatal("connect failed")
+	}
+	defer tw.Disconnect()
+	msg := waitMessage(t, messages)
+	if msg.Message != "点歌 晴天" || msg.User.Uid != "twitch:42" || msg.User.Username != "Viewer One" ||
+		!msg.User.Admin || msg.User.Privilege != twitchPrivilegeSubscriber || msg.User.Medal.Level != 14 {
+		t.Fatal("message is not parsed correctly")
+	}
+	select {
+	case line := <-pong:
+		if line != "PONG :tmi.twitch.tv" {
+			t.Fatalf("unexpected pong %s", line)
+		}
+	case <-time.After(twitchTestTimeout):
+		t.Fatal("ping is not answered")
+	}
+	if tw.SendMessage("hello") != ErrorNotLoggedIn {
+		t.Fatal("anonymous user should not be able to send message")
+	}
+}
+
+func TestTwitch_OAuth(t *testing.T) {
+	sent := make(chan string, 1)
+	address, closeServer := startIRCServer(t, func(conn *tcpIRCConn) {
+		if expectLine(conn, "PASS ") != "PASS oauth:token" || expectLine(conn, "NICK ") != "NICK user" {
+			t.Error("oauth login expected")
+			return
+		}
+		_ = conn.WriteLine(":tmi.twitch.tv 001 user :Welcome, GLHF!")
+		expectLine(conn, "JOIN ")
+		sent <- expectLine(conn, "PRIVMSG ")
+	})
+	defer closeServer()
+	tw, _ := newTestTwitch(address, TwitchAccount{Username: "User", Token: "oauth:token"})
+	if !tw.Connect() {
+		t.Fatal("connect failed")
+	}
+	defer tw.Disconnect()
+	if err := tw.SendMessage("hello\nworld"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case line := <-sent:
+		if line != "PRIVMSG #channel :hello world" {
+			t.Fatalf("unexpected message %s", line)
+		}
+	case <-time.After(twitchTestTimeout):
+		t.Fatal("message is not sent")
+	}
+}
+
+func TestTwitch_LoginFailed(t *testing.T) {
+	var count int32
+	address, closeServer := startIRCServer(t, func(conn *tcpIRCConn) {
+		atomic.AddInt32(&count, 1)
+		expectLine(conn, "NICK ")
+		_ = conn.WriteLine(":tmi.twitch.tv NOTICE * :Login authentication failed")
+		expectLine(conn, "QUIT")
+	})
+	defer closeServer()
+	tw, _ := newTestTwitch(address, TwitchAccount{Username: "user", Token: "wrong"})
+	status := make(chan StatusChangeEvent, 10)
+	tw.Handler().RegisterA(EventStatusChange, "test.twitch.status", func(event *event.Event) {
+		status <- event.Data.(StatusChangeEvent)
+	})
+	if tw.Connect() {
+		t.Fatal("connect should fail with wrong token")
+	}
+	select {
+	case s := <-status:
+		fmt.Println(s.Connected, s.Reconnecting, s.Error)
+		if s.Connected || s.Reconnecting || s.Error != ErrorLoginFailed {
+			t.Fatal("login failed status expected")
+		}
+	case <-time.After(twitchTestTimeout):
+		t.Fatal("login failed status is not sent")
+	}
+	// rejected login is not retried
+	time.Sleep(200 * time.Millisecond)
+	if atomic.LoadInt32(&count) != 1 {
+		t.Fatalf("expect 1 connection, got %d", atomic.LoadInt32(&count))
+	}
+}
+
+func TestTwitch_ConnectFailed(t *testing.T) {
+	// reserve an address, then close it so the first attempt is refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+	tw, _ := newTestTwitch(address, TwitchAccount{})
+	status := make(chan StatusChangeEvent, 10)
+	tw.Handler().RegisterA(EventStatusChange, "test.twitch.status", func(event *event.Event) {
+		status <- event.Data.(StatusChangeEvent)
+	})
+	if tw.Connect() {
+		t.Fatal("connect should fail when server is down")
+	}
+	defer tw.Disconnect()
+	select {
+	case s := <-status:
+		fmt.Println(s.Connected, s.Reconnecting, s.Attempt)
+		if !s.Reconnecting || s.Attempt != 1 {
+			t.Fatal("reconnecting status expected")
+		}
+	case <-time.After(twitchTestTimeout):
+		t.Fatal("failed connection is not retried")
+	}
+}
+
+func TestTwitch_Reconnect(t *testing.T) {
+	connections := make(chan int32, 10)
+	var count int32
+	address, closeServer := startIRCServer(t, func(conn *tcpIRCConn) {
+		n := atomic.AddInt32(&count, 1)
+		connections <- n
+		expectLine(conn, "NICK ")
+		_ = conn.WriteLine(":tmi.twitch.tv 001 justinfan :Welcome, GLHF!")
+		expectLine(conn, "JOIN ")
+		if n == 1 {
+			// first connection is closed by server
+			_ = conn.WriteLine(":tmi.twitch.tv RECONNECT")
+			return
+		}
+		expectLine(conn, "QUIT")
+	})
+	defer closeServer()
+	tw, _ := newTestTwitch(address, TwitchAccount{})
+	status := make(chan StatusChangeEvent, 10)
+	tw.Handler().RegisterA(EventStatusChange, "test.twitch.status", func(event *event.Event) {
+		status <- event.Data.(StatusChangeEvent)
+	})
+	if !tw.Connect() {
+		t.Fatal("connect failed")
+	}
+	reconnected := false
+	for !reconnected {
+		select {
+		case s := <-status:
+			fmt.Println(s.Connected, s.Reconnecting, s.Attempt)
+			reconnected = s.Connected && s.Attempt > 0
+		case <-time.After(twitchTestTimeout):
+			t.Fatal("not reconnected")
+		}
+	}
+	if len(connections) != 2 {
+		t.Fatalf("expect 2 connections, got %d", len(connections))
+	}
+	tw.Disconnect()
+	time.Sleep(200 * time.Millisecond)
+	if len(connections) != 2 {
+		t.Fatal("manual disconnect should not reconnect")
+	}
+}
+
+func TestTwitch_Websocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn := &wsIRCConn{conn: ws}
+		defer conn.Close()
+		expectLine(conn, "NICK ")
+		// multiple lines in one websocket message
+		_ = ws.WriteMessage(websocket.TextMessage, []byte(
+			":tmi.twitch.tv 001 justinfan :Welcome, GLHF!\r\n:tmi.twitch.tv 002 justinfan :Your host is tmi.twitch.tv\r\n"))
+		expectLine(conn, "JOIN ")
+		_ = conn.WriteLine("@badges=vip/1 :abc!abc@abc PRIVMSG #channel :\x01ACTION waves\x01")
+		expectLine(conn, "QUIT")
+	}))
+	defer server.Close()
+	tw, messages := newTestTwitch("ws://"+strings.TrimPrefix(server.URL, "http://"), TwitchAccount{})
+	if !tw.Connect() {
+		t.Fatal("connect failed")
+	}
+	defer tw.Disconnect()
+	msg := waitMessage(t, messages)
+	if msg.Message != "waves" || msg.User.Privilege != twitchPrivilegeVIP {
+		t.Fatal("websocket message is not parsed correctly")
+	}
+}
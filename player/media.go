@@ -20,10 +20,16 @@ type Media struct {
 	Cover  Picture
 	Album  string
 	Lyric  string
-	Url    string
-	Header map[string]string
-	User   interface{}
-	Meta   interface{}
+	// Duration in seconds, 0 means unknown
+	Duration int
+	Url      string
+	Header   map[string]string
+	User     interface{}
+	// Approver is who approved the request, empty if approval is not required
+	Approver string
+	// Priority of the request, medias with higher priority are placed before lower ones in user playlist
+	Priority int
+	Meta     interface{}
 }
 
 func (m *Media) ToUser() *User {
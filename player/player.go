@@ -150,8 +150,10 @@ func (p *Player) Seek(position float64, absolute bool) error {
 
 func (p *Player) ObserveProperty(property string, handler ...PropertyHandlerFunc) error {
 	p.l().Trace("add property observer for mpv")
+	observed := len(p.PropertyHandler[property]) > 0
 	p.PropertyHandler[property] = append(p.PropertyHandler[property], handler...)
-	if len(p.PropertyHandler[property]) == 1 {
+	// mpv only need to observe the property once, no matter how many handlers are added
+	if !observed && len(p.PropertyHandler[property]) > 0 {
 		return p.libmpv.ObserveProperty(util.Hash64(property), property, mpv.FORMAT_NODE)
 	}
 	return nil
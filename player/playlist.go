@@ -88,6 +88,17 @@ func (p *Playlist) Push(media *Media) {
 
 // Insert runtime in O(n) but i don't care
 func (p *Playlist) Insert(index int, media *Media) {
+	p.insert(media, index, nil)
+}
+
+// InsertBefore insert media before the first media matching before, or at the end if none matches.
+// position is found with the playlist locked, so concurrent changes won't make it stale.
+func (p *Playlist) InsertBefore(media *Media, before func(m *Media) bool) {
+	p.insert(media, -1, before)
+}
+
+// insert media at index, if before is not nil, index is the position of the first media matching it
+func (p *Playlist) insert(media *Media, index int, before func(m *Media) bool) {
 	p.l().Infof("insert new meida to index %d", index)
 	p.l().Debugf("media= %s", media.Title)
 	e := event.Event{
@@ -105,6 +116,14 @@ func (p *Playlist) Insert(index int, media *Media) {
 		return
 	}
 	p.Lock.Lock()
+	if before != nil {
+		for i, m := range p.Playlist {
+			if before(m) {
+				index = i
+				break
+			}
+		}
+	}
 	if index > p.Size() {
 		index = p.Size()
 	}
@@ -145,13 +164,79 @@ func (p *Playlist) Delete(index int) {
 	defer p.Handler.CallA(EventPlaylistUpdate, PlaylistUpdateEvent{Playlist: p})
 }
 
+// Remove delete media at index and return it, nil if not exists
+func (p *Playlist) Remove(index int) *Media {
+	p.l().Infof("remove media at index %d", index)
+	p.Lock.Lock()
+	if index >= p.Size() || index < 0 {
+		p.l().Warnf("media at index %d does not exist", index)
+		p.Lock.Unlock()
+		return nil
+	}
+	media := p.Playlist[index]
+	p.Playlist = append(p.Playlist[:index], p.Playlist[index+1:]...)
+	p.Lock.Unlock()
+	defer p.Handler.CallA(EventPlaylistUpdate, PlaylistUpdateEvent{Playlist: p})
+	return media
+}
+
+// DeleteMedia delete the media wherever it is, return false if it is not in playlist
+func (p *Playlist) DeleteMedia(media *Media) bool {
+	p.Lock.Lock()
+	index := p.indexOf(media)
+	if index < 0 {
+		p.Lock.Unlock()
+		return false
+	}
+	p.l().Infof("delete media %s at index %d", media.Title, index)
+	p.Playlist = append(p.Playlist[:index], p.Playlist[index+1:]...)
+	p.Lock.Unlock()
+	defer p.Handler.CallA(EventPlaylistUpdate, PlaylistUpdateEvent{Playlist: p})
+	return true
+}
+
+// indexOf return index of the media, -1 if not exists. lock should be held by caller.
+func (p *Playlist) indexOf(media *Media) int {
+	for i, m := range p.Playlist {
+		if m == media {
+			return i
+		}
+	}
+	return -1
+}
+
 func (p *Playlist) Move(src int, dest int) {
 	p.l().Infof("from media from index %d to %d", src, dest)
 	p.Lock.Lock()
+	moved := p.move(src, dest)
+	p.Lock.Unlock()
+	if moved {
+		p.Handler.CallA(EventPlaylistUpdate, PlaylistUpdateEvent{Playlist: p})
+	}
+}
+
+// MoveMedia move the media wherever it is to dest, return false if it is not in playlist
+func (p *Playlist) MoveMedia(media *Media, dest int) bool {
+	p.Lock.Lock()
+	src := p.indexOf(media)
+	if src < 0 {
+		p.Lock.Unlock()
+		return false
+	}
+	p.l().Infof("move media %s from index %d to %d", media.Title, src, dest)
+	moved := p.move(src, dest)
+	p.Lock.Unlock()
+	if moved {
+		p.Handler.CallA(EventPlaylistUpdate, PlaylistUpdateEvent{Playlist: p})
+	}
+	return true
+}
+
+// move media at src to dest, return false if nothing is moved. lock should be held by caller.
+func (p *Playlist) move(src int, dest int) bool {
 	if src >= p.Size() || src < 0 {
 		p.l().Warnf("media at index %d does not exist", src)
-		p.Lock.Unlock()
-		return
+		return false
 	}
 	if dest >= p.Size() {
 		dest = p.Size() - 1
@@ -161,8 +246,7 @@ func (p *Playlist) Move(src int, dest int) {
 	}
 	if dest == src {
 		p.l().Warn("src and dest are same, operation not perform")
-		p.Lock.Unlock()
-		return
+		return false
 	}
 	step := 1
 	if dest < src {
@@ -173,8 +257,7 @@ func (p *Playlist) Move(src int, dest int) {
 		p.Playlist[i] = p.Playlist[i+step]
 	}
 	p.Playlist[dest] = tmp
-	p.Lock.Unlock()
-	defer p.Handler.CallA(EventPlaylistUpdate, PlaylistUpdateEvent{Playlist: p})
+	return true
 }
 
 func (p *Playlist) Next() *Media {
@@ -20,3 +20,38 @@ func TestPlaylist_Insert(t *testing.T) {
 	}
 
 }
+
+func TestPlaylist_DeleteMedia(t *testing.T) {
+	pl := NewPlaylist("asdf", PlaylistConfig{RandomNext: false})
+	medias := make([]*Media, 5)
+	for i := range medias {
+		medias[i] = &Media{Url: strconv.Itoa(i)}
+		pl.Push(medias[i])
+	}
+	if pl.Remove(1) != medias[1] || pl.Remove(10) != nil {
+		t.Fatal("remove should return media at index")
+	}
+	if !pl.DeleteMedia(medias[3]) || pl.DeleteMedia(medias[3]) {
+		t.Fatal("media should be deleted only once")
+	}
+	if pl.Size() != 3 || pl.Playlist[0] != medias[0] || pl.Playlist[1] != medias[2] || pl.Playlist[2] != medias[4] {
+		t.Fatal("wrong medias are deleted")
+	}
+}
+
+func TestPlaylist_InsertBefore(t *testing.T) {
+	pl := NewPlaylist("asdf", PlaylistConfig{RandomNext: false})
+	for _, priority := range []int{2, 1, 0} {
+		pl.Push(&Media{Priority: priority})
+	}
+	m := &Media{Url: "paid", Priority: 1}
+	pl.InsertBefore(m, func(x *Media) bool { return x.Priority < m.Priority })
+	if pl.Playlist[2] != m {
+		t.Fatal("media should be inserted after medias with same priority")
+	}
+	last := &Media{Url: "last", Priority: -1}
+	pl.InsertBefore(last, func(x *Media) bool { return x.Priority < last.Priority })
+	if pl.Playlist[pl.Size()-1] != last {
+		t.Fatal("media should be inserted at the end if nothing matches")
+	}
+}
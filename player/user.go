@@ -6,3 +6,4 @@ type User struct {
 
 var PlaylistUser = &User{Name: "Playlist"}
 var SystemUser = &User{Name: "System"}
+var AutoplayUser = &User{Name: "Autoplay"}
@@ -0,0 +1,194 @@
+package blacklist
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/gui"
+	"AynaLivePlayer/i18n"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/logger"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/sirupsen/logrus"
+)
+
+const MODULE_PLUGIN_BLACKLIST = "plugin.blacklist"
+
+func l() *logrus.Entry {
+	return logger.Logger.WithField("Module", MODULE_PLUGIN_BLACKLIST)
+}
+
+type Blacklist struct {
+	BanSongCMD string
+	BanUserCMD string
+	banSong    *controller.Command
+	banUser    *controller.Command
+	panel      fyne.CanvasObject
+}
+
+func NewBlacklist() *Blacklist {
+	return &Blacklist{
+		BanSongCMD: "拉黑歌曲",
+		BanUserCMD: "拉黑用户",
+	}
+}
+
+func (b *Blacklist) Name() string {
+	return "Blacklist"
+}
+
+func (b *Blacklist) Enable() error {
+	config.LoadConfig(b)
+	b.banSong = &controller.Command{
+		Description: "拉黑当前歌曲并切歌",
+		Permission:  controller.PermissionAdmin,
+		Execute:     b.banCurrentSong,
+	}
+	b.banUser = &controller.Command{
+		Description: "拉黑用户, 不指定用户时拉黑当前歌曲的点歌人",
+		Args:        []controller.CommandArg{{Name: "user"}},
+		Permission:  controller.PermissionAdmin,
+		Execute:     b.banRequester,
+	}
+	b.updateCommands()
+	controller.RegisterCommand(b.banSong, b.banUser)
+	gui.AddConfigLayout(b)
+	return nil
+}
+
+func (b *Blacklist) Disable() error {
+	controller.Blacklist.Save()
+	return nil
+}
+
+// updateCommands apply config to the command specs, it should be called when config changes
+func (b *Blacklist) updateCommands() {
+	b.banSong.Name = b.BanSongCMD
+	b.banUser.Name = b.BanUserCMD
+}
+
+func (b *Blacklist) banCurrentSong(ctx *controller.CommandContext) error {
+	media := controller.CurrentMedia
+	if media == nil {
+		return nil
+	}
+	l().Infof("%s(%s) ban current song %s", ctx.User().Username, ctx.User().Uid, media.Title)
+	controller.AddBlacklistRule(&controller.BlacklistRule{
+		Type:  controller.BlacklistSong,
+		Value: controller.BlacklistSongValue(media),
+		Note:  media.Title,
+	}, ctx.Actor())
+	controller.Skip(ctx.Actor())
+	return nil
+}
+
+// findRequester find the requester in current media and user playlist by uid or username
+func findRequester(name string) *liveclient.DanmuUser {
+	if cm := controller.CurrentMedia; cm != nil && cm.DanmuUser() != nil {
+		if name == "" || cm.DanmuUser().Uid == name || cm.DanmuUser().Username == name {
+			return cm.DanmuUser()
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	controller.UserPlaylist.Lock.RLock()
+	defer controller.UserPlaylist.Lock.RUnlock()
+	for _, m := range controller.UserPlaylist.Playlist {
+		if u := m.DanmuUser(); u != nil && (u.Uid == name || u.Username == name) {
+			return u
+		}
+	}
+	return nil
+}
+
+// banRequester ban user by uid or username, ban the requester of current media if no argument provided
+func (b *Blacklist) banRequester(ctx *controller.CommandContext) error {
+	name := ctx.Arg("user")
+	rule := &controller.BlacklistRule{Type: controller.BlacklistUser, Value: name}
+	if u := findRequester(name); u != nil {
+		rule.Value = u.Uid
+		rule.Note = u.Username
+	}
+	if rule.Value == "" {
+		return nil
+	}
+	l().Infof("%s(%s) ban user %s", ctx.User().Username, ctx.User().Uid, rule.Value)
+	controller.AddBlacklistRule(rule, ctx.Actor())
+	return nil
+}
+
+func (b *Blacklist) Title() string {
+	return i18n.T("plugin.blacklist.title")
+}
+
+func (b *Blacklist) Description() string {
+	return i18n.T("plugin.blacklist.description")
+}
+
+func (b *Blacklist) CreatePanel() fyne.CanvasObject {
+	if b.panel != nil {
+		return b.panel
+	}
+	var rules *widget.List
+	rules = widget.NewList(
+		func() int {
+			return controller.Blacklist.Size()
+		},
+		func() fyne.CanvasObject {
+			return container.NewBorder(nil, nil, nil,
+				widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
+				widget.NewLabel("rule"))
+		},
+		func(id widget.ListItemID, object fyne.CanvasObject) {
+			rule := controller.Blacklist.Get(id)
+			if rule == nil {
+				return
+			}
+			object.(*fyne.Container).Objects[0].(*widget.Label).SetText(rule.String())
+			object.(*fyne.Container).Objects[1].(*widget.Button).OnTapped = func() {
+				controller.RemoveBlacklistRule(id, controller.ActorGUI)
+				rules.Refresh()
+			}
+		})
+	ruleTypes := make([]string, len(controller.BlacklistRuleTypes))
+	for i, t := range controller.BlacklistRuleTypes {
+		ruleTypes[i] = string(t)
+	}
+	typeSel := widget.NewSelect(ruleTypes, nil)
+	typeSel.SetSelected(ruleTypes[0])
+	valueEntry := widget.NewEntry()
+	valueEntry.SetPlaceHolder(i18n.T("plugin.blacklist.value.placeholder"))
+	addBtn := widget.NewButton(i18n.T("plugin.blacklist.add"), func() {
+		if controller.AddBlacklistRule(&controller.BlacklistRule{
+			Type:  controller.BlacklistRuleType(typeSel.Selected),
+			Value: valueEntry.Text,
+		}, controller.ActorGUI) {
+			valueEntry.SetText("")
+			rules.Refresh()
+		}
+	})
+	addRule := container.NewBorder(nil, nil,
+		container.NewHBox(widget.NewLabel(i18n.T("plugin.blacklist.rule")), typeSel), addBtn,
+		valueEntry)
+	cmdListener := binding.NewDataListener(b.updateCommands)
+	banSongBinding := binding.BindString(&b.BanSongCMD)
+	banSongBinding.AddListener(cmdListener)
+	banUserBinding := binding.BindString(&b.BanUserCMD)
+	banUserBinding.AddListener(cmdListener)
+	banSongCmd := container.NewBorder(nil, nil,
+		widget.NewLabel(i18n.T("plugin.blacklist.ban_song_cmd")), nil,
+		widget.NewEntryWithData(banSongBinding),
+	)
+	banUserCmd := container.NewBorder(nil, nil,
+		widget.NewLabel(i18n.T("plugin.blacklist.ban_user_cmd")), nil,
+		widget.NewEntryWithData(banUserBinding),
+	)
+	// a list in vbox has no height, so wrap it with a fixed size
+	ruleList := container.NewGridWrap(fyne.NewSize(640, 240), rules)
+	b.panel = container.NewVBox(banSongCmd, banUserCmd, addRule, ruleList)
+	return b.panel
+}
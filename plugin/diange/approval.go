@@ -0,0 +1,41 @@
+package diange
+
+import (
+	"AynaLivePlayer/controller"
+	"strconv"
+	"time"
+)
+
+// interval of checking expired pending requests
+const approvalCheckInterval = 10 * time.Second
+
+// executeApproval approve or reject pending request by its position (starting from 1),
+// the oldest one is used if no position is given.
+func (d *Diange) executeApproval(ctx *controller.CommandContext) error {
+	index := 0
+	if arg := ctx.Arg("position"); arg != "" {
+		pos, err := strconv.Atoi(arg)
+		if err != nil || pos < 1 {
+			l().Infof("invalid pending position %s", arg)
+			return controller.ErrorCommandArgs
+		}
+		index = pos - 1
+	}
+	approver := ctx.Actor()
+	if ctx.Command == d.approveCommand {
+		controller.Approve(index, approver)
+	} else {
+		controller.Reject(index, approver)
+	}
+	return nil
+}
+
+func (d *Diange) expirePendingLoop() {
+	for range time.Tick(approvalCheckInterval) {
+		controller.ExpireSelections()
+		if d.ApprovalTimeout <= 0 || controller.PendingPlaylist.Size() == 0 {
+			continue
+		}
+		controller.ExpirePending(time.Duration(d.ApprovalTimeout) * time.Second)
+	}
+}
@@ -3,14 +3,17 @@ package diange
 import (
 	"AynaLivePlayer/config"
 	"AynaLivePlayer/controller"
+	"AynaLivePlayer/event"
 	"AynaLivePlayer/gui"
 	"AynaLivePlayer/i18n"
 	"AynaLivePlayer/liveclient"
 	"AynaLivePlayer/logger"
-	"fmt"
+	"AynaLivePlayer/player"
+	"errors"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/widget"
 	"github.com/sirupsen/logrus"
 	"strings"
@@ -19,32 +22,94 @@ import (
 
 const MODULE_CMD_DIANGE = "CMD.DianGe"
 
+var (
+	ErrorQueueFull     = errors.New("request queue is full")
+	ErrorQuotaExceeded = errors.New("request quota exceeded")
+)
+
 func l() *logrus.Entry {
 	return logger.Logger.WithField("Module", MODULE_CMD_DIANGE)
 }
 
 type Diange struct {
-	UserPermission      bool
-	PrivilegePermission bool
-	AdminPermission     bool
-	QueueMax            int
-	UserCoolDown        int
-	CustomCMD           string
-	SourceCMD           []string
-	cooldowns           map[string]int
-	panel               fyne.CanvasObject
+	// Roles are roles allowed to request, see controller.Role
+	Roles        []string
+	QueueMax     int
+	UserCoolDown int
+	CustomCMD    string
+	SourceCMD    []string
+	// QuotaRoles, QuotaPending and QuotaHourly are quotas of roles in parallel slices
+	QuotaRoles         []string
+	QuotaPending       []int
+	QuotaHourly        []int
+	ApprovalMode       bool
+	ApprovalTimeout    int
+	ApproveCMD         string
+	RejectCMD          string
+	ReplySuccess       string
+	ReplyPending       string
+	ReplyQueueFull     string
+	ReplyQuota         string
+	ReplyNoResult      string
+	ReplyRejected      string
+	ReplyPaidRequired  string
+	PaidMode           bool
+	PaidSuperChat      bool
+	PaidSuperChatPrice float64
+	PaidGiftPrice      float64
+	PaidUnlockWindow   int
+	PaidPriorityPrice  float64
+	PaidJumpPrice      float64
+	SelectMode         bool
+	SelectCount        int
+	SelectTimeout      int
+	SelectCMD          string
+	ReplySelect        string
+	unlocks            *unlockStore
+	quota              *QuotaStore
+	auditor            *controller.ConfigAuditor
+	command            *controller.Command
+	approveCommand     *controller.Command
+	rejectCommand      *controller.Command
+	selectCommand      *controller.Command
+	roleGroup          *widget.CheckGroup
+	panel              fyne.CanvasObject
 }
 
 func NewDiange() *Diange {
 	return &Diange{
-		UserPermission:      true,
-		PrivilegePermission: true,
-		AdminPermission:     true,
-		QueueMax:            128,
-		UserCoolDown:        -1,
-		CustomCMD:           "add",
-		SourceCMD:           make([]string, 0),
-		cooldowns:           make(map[string]int),
+		Roles:              []string{controller.RoleUser, controller.RolePrivilege, controller.RoleAdmin},
+		QueueMax:           128,
+		UserCoolDown:       -1,
+		CustomCMD:          "add",
+		SourceCMD:          make([]string, 0),
+		QuotaRoles:         []string{controller.RoleUser, "medal", controller.RolePrivilege, controller.RoleAdmin},
+		QuotaPending:       []int{2, 3, 5, -1},
+		QuotaHourly:        []int{10, 15, 30, -1},
+		ApprovalMode:       false,
+		ApprovalTimeout:    600,
+		ApproveCMD:         "通过",
+		RejectCMD:          "拒绝",
+		ReplySuccess:       "已点歌: {title} - 第{pos}位",
+		ReplyPending:       "{title} 等待审核",
+		ReplyQueueFull:     "点歌队列已满",
+		ReplyQuota:         "{user} 点歌次数已达上限",
+		ReplyNoResult:      "没有找到 {keyword}",
+		ReplyRejected:      "{user} 点歌被拒绝",
+		ReplyPaidRequired:  "{user} 送礼物后才能点歌",
+		PaidMode:           false,
+		PaidSuperChat:      true,
+		PaidSuperChatPrice: 30,
+		PaidGiftPrice:      1,
+		PaidUnlockWindow:   10,
+		PaidPriorityPrice:  30,
+		PaidJumpPrice:      100,
+		SelectMode:         false,
+		SelectCount:        3,
+		SelectTimeout:      30,
+		SelectCMD:          "选",
+		ReplySelect:        "{user} 发送 选+序号 选择: {candidates}",
+		unlocks:            newUnlockStore(),
 	}
 }
 
@@ -54,16 +119,71 @@ func (d *Diange) Name() string {
 
 func (d *Diange) Enable() error {
 	config.LoadConfig(d)
+	d.migratePermission()
+	d.normalizeQuota()
+	d.quota = newQuotaStore(QuotaStorePath)
 	d.initCMD()
-	controller.AddCommand(d)
+	d.auditor = controller.NewConfigAuditor(controller.ActorGUI, d)
+	d.command = &controller.Command{
+		Name:        "点歌",
+		Description: "点歌, 可以用对应来源的指令指定来源",
+		Args:        []controller.CommandArg{{Name: "keyword", Required: true, Rest: true}},
+		Cooldowns:   controller.NewCooldownStore(CooldownStorePath),
+		Execute:     d.execute,
+	}
+	d.approveCommand = &controller.Command{
+		Description: "通过待审核的点歌",
+		Args:        []controller.CommandArg{{Name: "position"}},
+		Permission:  controller.PermissionAdmin,
+		Execute:     d.executeApproval,
+	}
+	d.rejectCommand = &controller.Command{
+		Description: "拒绝待审核的点歌",
+		Args:        []controller.CommandArg{{Name: "position"}},
+		Permission:  controller.PermissionAdmin,
+		Execute:     d.executeApproval,
+	}
+	d.selectCommand = &controller.Command{
+		Description: "从点歌的搜索结果中选择",
+		Args:        []controller.CommandArg{{Name: "position", Required: true}},
+		Permission:  controller.PermissionEveryone,
+		Execute:     d.executeSelect,
+	}
+	d.updateCommands()
+	go d.expirePendingLoop()
+	controller.EventHandler.RegisterA(controller.EventScheduleTrigger, "plugin.diange.schedule", d.handleSchedule)
+	controller.EventHandler.RegisterA(liveclient.EventSuperChat, "plugin.diange.paid.superchat", d.handleSuperChat)
+	controller.EventHandler.RegisterA(liveclient.EventGiftReceive, "plugin.diange.paid.gift", d.handleGift)
+	controller.EventHandler.RegisterA(liveclient.EventGuardBuy, "plugin.diange.paid.guard", d.handleGuardBuy)
+	controller.RegisterCommand(d.command, d.approveCommand, d.rejectCommand, d.selectCommand)
 	gui.AddConfigLayout(d)
 	return nil
 }
 
 func (d *Diange) Disable() error {
+	d.quota.Save()
 	return nil
 }
 
+// migratePermission convert permissions before roles were introduced into Roles
+func (d *Diange) migratePermission() {
+	perm, ok := config.LoadLegacyPermission(d.Name())
+	if !ok {
+		return
+	}
+	d.Roles = make([]string, 0)
+	if perm.User {
+		d.Roles = append(d.Roles, controller.RoleUser)
+	}
+	if perm.Privilege {
+		d.Roles = append(d.Roles, controller.RolePrivilege)
+	}
+	if perm.Admin {
+		d.Roles = append(d.Roles, controller.RoleAdmin)
+	}
+	l().Infof("migrate permission %+v to roles %v", perm, d.Roles)
+}
+
 func (d *Diange) initCMD() {
 	if len(d.SourceCMD) == len(config.Provider.Priority) {
 		return
@@ -77,56 +197,113 @@ func (d *Diange) initCMD() {
 	}
 }
 
-// isCMD return int if the commmand name matches our command
-// -1 = not match, 0 = normal command, 1+ = source command
-func (d *Diange) isCMD(cmd string) int {
-	if cmd == "点歌" || cmd == d.CustomCMD {
-		return 0
-	}
-	fmt.Println(d.SourceCMD)
+// updateCommands apply config to the command specs, it should be called when config changes
+func (d *Diange) updateCommands() {
+	d.command.Aliases = append([]string{d.CustomCMD}, d.SourceCMD...)
+	d.command.Permission = d.Roles
+	d.command.UserCooldown = d.UserCoolDown
+	d.approveCommand.Name = d.ApproveCMD
+	d.rejectCommand.Name = d.RejectCMD
+	d.selectCommand.Name = d.SelectCMD
+}
+
+// providerOf return the provider of the source command, empty string means all providers
+func (d *Diange) providerOf(cmd string) string {
 	for index, c := range d.SourceCMD {
 		if cmd == c {
-			return index + 1
+			return config.Provider.Priority[index]
 		}
 	}
-	return -1
-}
-
-func (d *Diange) Match(command string) bool {
-	return d.isCMD(command) >= 0
+	return ""
 }
 
-func (d *Diange) Execute(command string, args []string, danmu *liveclient.DanmuMessage) {
-	l().Infof("%s(%s) Execute command: %s %s", danmu.User.Username, danmu.User.Uid, command, args)
+func (d *Diange) execute(ctx *controller.CommandContext) error {
+	user := ctx.User()
+	keyword := ctx.Arg("keyword")
+	pname := d.providerOf(ctx.Name)
+	if d.PaidMode && !controller.HasRole(user, controller.RoleAdmin) {
+		return d.executePaid(user, keyword, pname)
+	}
 	// if queue is full, return
 	if controller.UserPlaylist.Size() >= d.QueueMax {
 		l().Info("Queue is full, ignore diange")
-		return
+		controller.ReplyTemplate(user, d.ReplyQueueFull, nil)
+		return ErrorQueueFull
 	}
-	// if in user cool down, return
 	ct := int(time.Now().Unix())
-	if (ct - d.cooldowns[danmu.User.Uid]) <= d.UserCoolDown {
-		l().Infof("User %s(%s) still in cool down period, diange failed", danmu.User.Username, danmu.User.Uid)
+	if !d.checkQuota(user, ct) {
+		controller.ReplyTemplate(user, d.ReplyQuota, nil)
+		return ErrorQuotaExceeded
+	}
+	if d.SelectMode {
+		// quota is recorded when user selects a candidate
+		return d.startSelection(user, keyword, pname)
+	}
+	if err := d.request(user, keyword, pname, controller.PriorityNormal); err != nil {
+		return err
+	}
+	// failed requests don't use up quota
+	d.quota.Record(user.Uid, ct)
+	return nil
+}
+
+// request add the media to user playlist, or pending list if approval is required
+func (d *Diange) request(user *liveclient.DanmuUser, keyword string, pname string, priority int) error {
+	media, err := controller.ResolveRequest(keyword, pname, user)
+	if err != nil {
+		d.replyError(user, err, map[string]interface{}{"keyword": keyword})
+		return err
+	}
+	d.addMedia(user, media, priority)
+	return nil
+}
+
+// addMedia add media which has passed controller.CheckRequest
+func (d *Diange) addMedia(user *liveclient.DanmuUser, media *player.Media, priority int) {
+	values := map[string]interface{}{"title": media.Title, "artist": media.Artist}
+	if d.ApprovalMode && !controller.HasRole(user, controller.RoleAdmin) {
+		controller.AddPendingRequest(media, priority)
+		values["pos"] = controller.PendingPlaylist.Size()
+		controller.ReplyTemplate(user, d.ReplyPending, values)
 		return
 	}
-	cmdType := d.isCMD(command)
-	keyword := strings.Join(args, " ")
-	perm := d.UserPermission
-	l().Trace("user permission check: ", perm)
-	perm = perm || (d.PrivilegePermission && (danmu.User.Privilege > 0))
-	l().Trace("privilege permission check: ", perm)
-	perm = perm || (d.AdminPermission && (danmu.User.Admin))
-	l().Trace("admin permission check: ", perm)
-	if !perm {
+	controller.AddRequest(media, priority)
+	values["pos"] = controller.RequestPosition(media) + 1
+	controller.ReplyTemplate(user, d.ReplySuccess, values)
+}
+
+// replyError explain why the request failed
+func (d *Diange) replyError(user *liveclient.DanmuUser, err error, values map[string]interface{}) {
+	if err == controller.ErrorNoResult {
+		controller.ReplyTemplate(user, d.ReplyNoResult, values)
 		return
 	}
-	// reset cool down
-	d.cooldowns[danmu.User.Uid] = ct
-	if cmdType == 0 {
-		controller.Add(keyword, &danmu.User)
-	} else {
-		controller.AddWithProvider(keyword, config.Provider.Priority[cmdType-1], &danmu.User)
+	controller.ReplyTemplate(user, d.ReplyRejected, values)
+}
+
+// handleSchedule change permissions by schedule, value is roles allowed to request
+// separated by comma, or none to disable requests.
+func (d *Diange) handleSchedule(event *event.Event) {
+	rule := event.Data.(controller.ScheduleTriggerEvent).Rule
+	if rule.Action != controller.ScheduleActionDiange {
+		return
 	}
+	roles := make([]string, 0)
+	for _, role := range strings.Split(rule.Value, ",") {
+		if role = strings.TrimSpace(role); role != "" && role != "none" {
+			roles = append(roles, role)
+		}
+	}
+	// changes before are made in gui, the schedule itself is audited by controller
+	d.auditor.Flush()
+	d.Roles = roles
+	d.auditor.Sync()
+	l().Infof("schedule change roles allowed to request to %v", d.Roles)
+	// roles are changed outside gui, reload checkbox states
+	if d.roleGroup != nil {
+		d.roleGroup.SetSelected(d.Roles)
+	}
+	d.updateCommands()
 }
 
 func (d *Diange) Title() string {
@@ -141,23 +318,31 @@ func (d *Diange) CreatePanel() fyne.CanvasObject {
 	if d.panel != nil {
 		return d.panel
 	}
-	dgPerm := container.NewHBox(
-		widget.NewLabel(i18n.T("plugin.diange.permission")),
-		widget.NewCheckWithData(i18n.T("plugin.diange.user"), binding.BindBool(&d.UserPermission)),
-		widget.NewCheckWithData(i18n.T("plugin.diange.privilege"), binding.BindBool(&d.PrivilegePermission)),
-		widget.NewCheckWithData(i18n.T("plugin.diange.admin"), binding.BindBool(&d.AdminPermission)),
+	// command specs are updated when related config changed, all changes are audited
+	auditListener := binding.NewDataListener(d.auditor.Changed)
+	bind := gui.NewConfigBinder(auditListener)
+	bindCmd := gui.NewConfigBinder(auditListener, binding.NewDataListener(d.updateCommands))
+	cooldown := bindCmd.Int(&d.UserCoolDown)
+	d.roleGroup = gui.NewRoleCheckGroup(d.Roles, func(roles []string) {
+		d.Roles = roles
+		d.updateCommands()
+		d.auditor.Changed()
+	})
+	dgPerm := container.NewBorder(nil, nil,
+		widget.NewLabel(i18n.T("plugin.diange.permission")), nil,
+		d.roleGroup,
 	)
 	dgQueue := container.NewBorder(nil, nil,
 		widget.NewLabel(i18n.T("plugin.diange.queue_max")), nil,
-		widget.NewEntryWithData(binding.IntToString(binding.BindInt(&d.QueueMax))),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&d.QueueMax))),
 	)
 	dgCoolDown := container.NewBorder(nil, nil,
 		widget.NewLabel(i18n.T("plugin.diange.cooldown")), nil,
-		widget.NewEntryWithData(binding.IntToString(binding.BindInt(&d.UserCoolDown))),
+		widget.NewEntryWithData(binding.IntToString(cooldown)),
 	)
 	dgShortCut := container.NewBorder(nil, nil,
 		widget.NewLabel(i18n.T("plugin.diange.custom_cmd")), nil,
-		widget.NewEntryWithData(binding.BindString(&d.CustomCMD)),
+		widget.NewEntryWithData(bindCmd.String(&d.CustomCMD)),
 	)
 	sourceCmds := []fyne.CanvasObject{}
 	for i, _ := range d.SourceCMD {
@@ -165,11 +350,101 @@ func (d *Diange) CreatePanel() fyne.CanvasObject {
 			sourceCmds,
 			container.NewBorder(
 				nil, nil, widget.NewLabel(config.Provider.Priority[i]), nil,
-				widget.NewEntryWithData(binding.BindString(&d.SourceCMD[i]))))
+				widget.NewEntryWithData(bindCmd.String(&d.SourceCMD[i]))))
 	}
 	dgSourceCMD := container.NewBorder(
 		nil, nil, widget.NewLabel(i18n.T("plugin.diange.source_cmd")), nil,
 		container.NewVBox(sourceCmds...))
-	d.panel = container.NewVBox(dgPerm, dgQueue, dgCoolDown, dgShortCut, dgSourceCMD)
+	quotaForm := []fyne.CanvasObject{
+		widget.NewLabel(i18n.T("plugin.diange.quota.role")),
+		widget.NewLabel(i18n.T("plugin.diange.quota.pending")),
+		widget.NewLabel(i18n.T("plugin.diange.quota.hourly")),
+	}
+	for i := range d.QuotaRoles {
+		role := widget.NewSelectEntry(controller.RoleNames())
+		role.Bind(bind.String(&d.QuotaRoles[i]))
+		quotaForm = append(quotaForm,
+			role,
+			widget.NewEntryWithData(binding.IntToString(bind.Int(&d.QuotaPending[i]))),
+			widget.NewEntryWithData(binding.IntToString(bind.Int(&d.QuotaHourly[i]))),
+		)
+	}
+	dgQuota := container.NewVBox(
+		widget.NewLabel(i18n.T("plugin.diange.quota")),
+		container.NewGridWithColumns(3, quotaForm...),
+	)
+	dgApproval := container.NewVBox(
+		widget.NewCheckWithData(i18n.T("plugin.diange.approval.mode"), bind.Bool(&d.ApprovalMode)),
+		container.NewBorder(nil, nil,
+			widget.NewLabel(i18n.T("plugin.diange.approval.timeout")), nil,
+			widget.NewEntryWithData(binding.IntToString(bind.Int(&d.ApprovalTimeout))),
+		),
+		container.NewGridWithColumns(2,
+			container.NewBorder(nil, nil,
+				widget.NewLabel(i18n.T("plugin.diange.approval.approve_cmd")), nil,
+				widget.NewEntryWithData(bindCmd.String(&d.ApproveCMD))),
+			container.NewBorder(nil, nil,
+				widget.NewLabel(i18n.T("plugin.diange.approval.reject_cmd")), nil,
+				widget.NewEntryWithData(bindCmd.String(&d.RejectCMD))),
+		),
+	)
+	paidForm := make([]fyne.CanvasObject, 0)
+	for _, p := range []struct {
+		key   string
+		value *float64
+	}{
+		{"superchat_price", &d.PaidSuperChatPrice},
+		{"gift_price", &d.PaidGiftPrice},
+		{"priority_price", &d.PaidPriorityPrice},
+		{"jump_price", &d.PaidJumpPrice},
+	} {
+		paidForm = append(paidForm,
+			widget.NewLabel(i18n.T("plugin.diange.paid."+p.key)),
+			widget.NewEntryWithData(binding.FloatToString(bind.Float(p.value))))
+	}
+	paidForm = append(paidForm,
+		widget.NewLabel(i18n.T("plugin.diange.paid.unlock_window")),
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&d.PaidUnlockWindow))))
+	dgPaid := container.NewVBox(
+		container.NewHBox(
+			widget.NewCheckWithData(i18n.T("plugin.diange.paid.mode"), bind.Bool(&d.PaidMode)),
+			widget.NewCheckWithData(i18n.T("plugin.diange.paid.superchat"), bind.Bool(&d.PaidSuperChat)),
+		),
+		container.New(layout.NewFormLayout(), paidForm...),
+	)
+	dgSelect := container.NewVBox(
+		widget.NewCheckWithData(i18n.T("plugin.diange.select.mode"), bind.Bool(&d.SelectMode)),
+		container.New(layout.NewFormLayout(),
+			widget.NewLabel(i18n.T("plugin.diange.select.count")),
+			widget.NewEntryWithData(binding.IntToString(bind.Int(&d.SelectCount))),
+			widget.NewLabel(i18n.T("plugin.diange.select.timeout")),
+			widget.NewEntryWithData(binding.IntToString(bind.Int(&d.SelectTimeout))),
+			widget.NewLabel(i18n.T("plugin.diange.select.cmd")),
+			widget.NewEntryWithData(bindCmd.String(&d.SelectCMD)),
+		),
+	)
+	replyForm := make([]fyne.CanvasObject, 0)
+	for _, r := range []struct {
+		key   string
+		value *string
+	}{
+		{"success", &d.ReplySuccess},
+		{"pending", &d.ReplyPending},
+		{"queue_full", &d.ReplyQueueFull},
+		{"quota", &d.ReplyQuota},
+		{"no_result", &d.ReplyNoResult},
+		{"rejected", &d.ReplyRejected},
+		{"paid_required", &d.ReplyPaidRequired},
+		{"select", &d.ReplySelect},
+	} {
+		replyForm = append(replyForm,
+			widget.NewLabel(i18n.T("plugin.diange.reply."+r.key)),
+			widget.NewEntryWithData(bind.String(r.value)))
+	}
+	dgReply := container.NewVBox(
+		widget.NewLabel(i18n.T("plugin.diange.reply")),
+		container.New(layout.NewFormLayout(), replyForm...),
+	)
+	d.panel = container.NewVBox(dgPerm, dgQueue, dgCoolDown, dgShortCut, dgSourceCMD, dgQuota, dgApproval, dgPaid, dgSelect, dgReply)
 	return d.panel
 }
@@ -0,0 +1,120 @@
+package diange
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/event"
+	"AynaLivePlayer/liveclient"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrorPaidRequired = errors.New("request is not paid")
+
+type unlock struct {
+	Value  float64
+	Expire time.Time
+}
+
+// unlockStore keep the value of gifts sent by users in unlock window,
+// a user can request once the value reaches the gift price.
+type unlockStore struct {
+	unlocks map[string]*unlock
+	lock    sync.Mutex
+}
+
+func newUnlockStore() *unlockStore {
+	return &unlockStore{unlocks: make(map[string]*unlock)}
+}
+
+// Add add value to the unlock of user and extend the expire time
+func (s *unlockStore) Add(uid string, value float64, expire time.Time) float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	u, ok := s.unlocks[uid]
+	if !ok || u.Expire.Before(time.Now()) {
+		u = &unlock{}
+		s.unlocks[uid] = u
+	}
+	u.Value += value
+	u.Expire = expire
+	return u.Value
+}
+
+// Take remove the unlock of user and return it if it is not expired and reaches price
+func (s *unlockStore) Take(uid string, price float64) (*unlock, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	u, ok := s.unlocks[uid]
+	if !ok || u.Expire.Before(time.Now()) || u.Value < price {
+		return nil, false
+	}
+	delete(s.unlocks, uid)
+	return u, true
+}
+
+// priorityOf map paid value to request priority, non positive price means disabled
+func (d *Diange) priorityOf(value float64) int {
+	if d.PaidJumpPrice > 0 && value >= d.PaidJumpPrice {
+		return controller.PriorityJump
+	}
+	if d.PaidPriorityPrice > 0 && value >= d.PaidPriorityPrice {
+		return controller.PriorityPaid
+	}
+	return controller.PriorityNormal
+}
+
+// executePaid request with the unlock of gifts, paid requests are not limited by queue size and quota
+func (d *Diange) executePaid(user *liveclient.DanmuUser, keyword string, pname string) error {
+	u, ok := d.unlocks.Take(user.Uid, d.PaidGiftPrice)
+	if !ok {
+		l().Infof("%s(%s) has not paid for request", user.Username, user.Uid)
+		controller.ReplyTemplate(user, d.ReplyPaidRequired, nil)
+		return ErrorPaidRequired
+	}
+	if err := d.request(user, keyword, pname, d.priorityOf(u.Value)); err != nil {
+		// nothing is requested, give the paid value back
+		d.unlocks.Add(user.Uid, u.Value, u.Expire)
+		return err
+	}
+	return nil
+}
+
+// addUnlock record value of gifts and guard purchases sent by user
+func (d *Diange) addUnlock(user *liveclient.DanmuUser, value float64, currency liveclient.Currency) {
+	if !d.PaidMode || currency != liveclient.CurrencyCNY || value <= 0 {
+		return
+	}
+	total := d.unlocks.Add(user.Uid, value, time.Now().Add(time.Duration(d.PaidUnlockWindow)*time.Minute))
+	l().Infof("%s(%s) has paid %.1f for request", user.Username, user.Uid, total)
+}
+
+func (d *Diange) handleGift(event *event.Event) {
+	msg := event.Data.(*liveclient.GiftMessage)
+	d.addUnlock(&msg.User, msg.Value, msg.Currency)
+}
+
+func (d *Diange) handleGuardBuy(event *event.Event) {
+	msg := event.Data.(*liveclient.GuardMessage)
+	d.addUnlock(&msg.User, msg.Value, msg.Currency)
+}
+
+// handleSuperChat request with the message of SuperChat as keyword,
+// diange command in the message is removed.
+func (d *Diange) handleSuperChat(event *event.Event) {
+	msg := event.Data.(*liveclient.SuperChatMessage)
+	if !d.PaidMode || !d.PaidSuperChat || msg.Currency != liveclient.CurrencyCNY || msg.Value < d.PaidSuperChatPrice {
+		return
+	}
+	keyword, pname := strings.TrimSpace(msg.Message), ""
+	if args := strings.Fields(keyword); len(args) > 1 && d.command.Match(args[0]) {
+		keyword = strings.TrimSpace(strings.TrimPrefix(keyword, args[0]))
+		pname = d.providerOf(args[0])
+	}
+	if keyword == "" {
+		return
+	}
+	l().Infof("%s(%s) request %s by superchat %.1f", msg.User.Username, msg.User.Uid, keyword, msg.Value)
+	_ = d.request(&msg.User, keyword, pname, d.priorityOf(msg.Value))
+}
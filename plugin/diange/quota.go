@@ -0,0 +1,152 @@
+package diange
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/util"
+	"sync"
+	"time"
+)
+
+const QuotaStorePath = "./diange_quota.json"
+
+// CooldownStorePath persist cooldowns of diange command
+const CooldownStorePath = "./diange_cooldown.json"
+
+// quota window for hourly request limit, in seconds
+const quotaWindow = 3600
+
+// QuotaStore keep the request history of every user, it is saved whenever
+// a request is recorded so restart or crash won't reset user's limits.
+type QuotaStore struct {
+	Requests map[string][]int
+	filename string
+	lock     sync.Mutex
+}
+
+func newQuotaStore(filename string) *QuotaStore {
+	s := &QuotaStore{
+		Requests: make(map[string][]int),
+		filename: filename,
+	}
+	if err := util.LoadJson(filename, s); err != nil {
+		l().Infof("load quota store from %s failed: %s", filename, err)
+	}
+	if s.Requests == nil {
+		s.Requests = make(map[string][]int)
+	}
+	return s
+}
+
+func (s *QuotaStore) Save() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.save()
+}
+
+func (s *QuotaStore) save() {
+	s.prune(int(time.Now().Unix()))
+	if err := util.SaveJson(s.filename, s); err != nil {
+		l().Warnf("save quota store to %s failed: %s", s.filename, err)
+	}
+}
+
+// prune remove request records out of quota window
+func (s *QuotaStore) prune(now int) {
+	for uid, reqs := range s.Requests {
+		valid := make([]int, 0, len(reqs))
+		for _, t := range reqs {
+			if now-t < quotaWindow {
+				valid = append(valid, t)
+			}
+		}
+		if len(valid) == 0 {
+			delete(s.Requests, uid)
+		} else {
+			s.Requests[uid] = valid
+		}
+	}
+}
+
+// RequestsInWindow return number of requests of the user in recent quota window
+func (s *QuotaStore) RequestsInWindow(uid string, now int) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cnt := 0
+	for _, t := range s.Requests[uid] {
+		if now-t < quotaWindow {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+// Record add a request of the user and save the store
+func (s *QuotaStore) Record(uid string, now int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Requests[uid] = append(s.Requests[uid], now)
+	s.save()
+}
+
+// quotaLimit return the most generous limit among roles of the user, negative limit means unlimited.
+// user without any quota role is unlimited.
+func (d *Diange) quotaLimit(user *liveclient.DanmuUser, limits []int) int {
+	limit, found := 0, false
+	for i, role := range d.QuotaRoles {
+		if i >= len(limits) || !controller.HasRole(user, role) {
+			continue
+		}
+		if limits[i] < 0 {
+			return -1
+		}
+		if !found || limits[i] > limit {
+			limit, found = limits[i], true
+		}
+	}
+	if !found {
+		return -1
+	}
+	return limit
+}
+
+// pendingCount return number of medias requested by the user in user playlist and pending list
+func pendingCount(uid string) int {
+	cnt := 0
+	for _, p := range []*player.Playlist{controller.UserPlaylist, controller.PendingPlaylist} {
+		p.Lock.RLock()
+		for _, m := range p.Playlist {
+			if u := m.DanmuUser(); u != nil && u.Uid == uid {
+				cnt++
+			}
+		}
+		p.Lock.RUnlock()
+	}
+	return cnt
+}
+
+// checkQuota return false if user exceed the pending or hourly quota of their roles
+func (d *Diange) checkQuota(user *liveclient.DanmuUser, now int) bool {
+	if limit := d.quotaLimit(user, d.QuotaPending); limit >= 0 && pendingCount(user.Uid) >= limit {
+		l().Infof("User %s(%s) has reached pending quota %d", user.Username, user.Uid, limit)
+		return false
+	}
+	if limit := d.quotaLimit(user, d.QuotaHourly); limit >= 0 && d.quota.RequestsInWindow(user.Uid, now) >= limit {
+		l().Infof("User %s(%s) has reached hourly quota %d", user.Username, user.Uid, limit)
+		return false
+	}
+	return true
+}
+
+// normalizeQuota make sure there is a quota for every quota role
+func (d *Diange) normalizeQuota() {
+	for len(d.QuotaPending) < len(d.QuotaRoles) {
+		d.QuotaPending = append(d.QuotaPending, -1)
+	}
+	for len(d.QuotaHourly) < len(d.QuotaRoles) {
+		d.QuotaHourly = append(d.QuotaHourly, -1)
+	}
+	d.QuotaPending = d.QuotaPending[:len(d.QuotaRoles)]
+	d.QuotaHourly = d.QuotaHourly[:len(d.QuotaRoles)]
+}
@@ -0,0 +1,49 @@
+package diange
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/liveclient"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// startSelection search keyword and reply candidates for the user to choose
+func (d *Diange) startSelection(user *liveclient.DanmuUser, keyword string, pname string) error {
+	s, err := controller.StartSelection(keyword, pname, user, d.SelectCount, time.Duration(d.SelectTimeout)*time.Second)
+	if err != nil {
+		d.replyError(user, err, map[string]interface{}{"keyword": keyword})
+		return err
+	}
+	candidates := make([]string, len(s.Candidates))
+	for i, m := range s.Candidates {
+		candidates[i] = fmt.Sprintf("%d.%s-%s", i+1, m.Title, m.Artist)
+	}
+	controller.ReplyTemplate(user, d.ReplySelect, map[string]interface{}{
+		"keyword":    keyword,
+		"candidates": strings.Join(candidates, " "),
+		"timeout":    d.SelectTimeout,
+	})
+	return nil
+}
+
+// executeSelect add the candidate chosen by user, position starts from 1
+func (d *Diange) executeSelect(ctx *controller.CommandContext) error {
+	pos, err := strconv.Atoi(ctx.Arg("position"))
+	if err != nil || pos < 1 {
+		return controller.ErrorCommandArgs
+	}
+	user := ctx.User()
+	media, err := controller.TakeSelection(user, pos-1)
+	if err != nil {
+		l().Infof("%s(%s) select %d failed: %s", user.Username, user.Uid, pos, err)
+		if err != controller.ErrorNoSelection && err != controller.ErrorCommandArgs {
+			d.replyError(user, err, nil)
+		}
+		return err
+	}
+	d.addMedia(user, media, controller.PriorityNormal)
+	d.quota.Record(user.Uid, int(time.Now().Unix()))
+	return nil
+}
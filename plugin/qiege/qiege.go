@@ -3,10 +3,11 @@ package qiege
 import (
 	"AynaLivePlayer/config"
 	"AynaLivePlayer/controller"
+	"AynaLivePlayer/event"
 	"AynaLivePlayer/gui"
 	"AynaLivePlayer/i18n"
-	"AynaLivePlayer/liveclient"
 	"AynaLivePlayer/logger"
+	"AynaLivePlayer/player"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
@@ -16,24 +17,41 @@ import (
 
 const MODULE_CMD_QieGE = "CMD.QieGe"
 
+// voteActor is recorded in audit log when current media is skipped by vote
+var voteActor = controller.AuditActor{Type: controller.AuditActorSystem, Name: "Vote"}
+
 func l() *logrus.Entry {
 	return logger.Logger.WithField("Module", MODULE_CMD_QieGE)
 }
 
 type Qiege struct {
-	UserPermission      bool
-	PrivilegePermission bool
-	AdminPermission     bool
-	CustomCMD           string
-	panel               fyne.CanvasObject
+	// SelfSkip allow requester to skip their own request
+	SelfSkip bool
+	// Roles are roles allowed to skip directly, others can only vote
+	Roles         []string
+	CustomCMD     string
+	VoteMode      bool
+	VoteThreshold int
+	VotePercent   int
+	VoteWindow    int
+	ActiveWindow  int
+	vote          *skipVote
+	auditor       *controller.ConfigAuditor
+	command       *controller.Command
+	panel         fyne.CanvasObject
 }
 
 func NewQiege() *Qiege {
 	return &Qiege{
-		UserPermission:      true,
-		PrivilegePermission: true,
-		AdminPermission:     true,
-		CustomCMD:           "skip",
+		SelfSkip:      true,
+		Roles:         []string{controller.RolePrivilege, controller.RoleAdmin},
+		CustomCMD:     "skip",
+		VoteMode:      false,
+		VoteThreshold: 5,
+		VotePercent:   0,
+		VoteWindow:    120,
+		ActiveWindow:  600,
+		vote:          newSkipVote(),
 	}
 }
 
@@ -43,39 +61,72 @@ func (d *Qiege) Name() string {
 
 func (d *Qiege) Enable() error {
 	config.LoadConfig(d)
-	controller.AddCommand(d)
+	d.migratePermission()
+	d.auditor = controller.NewConfigAuditor(controller.ActorGUI, d)
+	d.command = &controller.Command{
+		Name:        "切歌",
+		Description: "切掉当前歌曲, 或者投票切歌",
+		Permission:  controller.PermissionEveryone,
+		Execute:     d.execute,
+	}
+	d.updateCommands()
+	controller.RegisterCommand(d.command)
+	controller.AddDanmuHandler(&chatterRecorder{vote: d.vote})
+	controller.MainPlayer.EventHandler.RegisterA(player.EventPlay, "plugin.qiege.vote", func(event *event.Event) {
+		if d.VoteMode {
+			d.publishReset(event.Data.(player.PlayEvent).Media)
+		}
+	})
 	gui.AddConfigLayout(d)
 	return nil
 }
 
+// migratePermission convert permissions before roles were introduced,
+// user permission meant requester can skip their own request.
+func (d *Qiege) migratePermission() {
+	perm, ok := config.LoadLegacyPermission(d.Name())
+	if !ok {
+		return
+	}
+	d.SelfSkip = perm.User
+	d.Roles = make([]string, 0)
+	if perm.Privilege {
+		d.Roles = append(d.Roles, controller.RolePrivilege)
+	}
+	if perm.Admin {
+		d.Roles = append(d.Roles, controller.RoleAdmin)
+	}
+	l().Infof("migrate permission %+v to self skip %t and roles %v", perm, d.SelfSkip, d.Roles)
+}
+
 func (d *Qiege) Disable() error {
 	return nil
 }
 
-func (d *Qiege) Match(command string) bool {
-	for _, c := range []string{"切歌", d.CustomCMD} {
-		if command == c {
-			return true
-		}
-	}
-	return false
+// updateCommands apply config to the command spec, it should be called when config changes
+func (d *Qiege) updateCommands() {
+	d.command.Aliases = []string{d.CustomCMD}
 }
 
-func (d *Qiege) Execute(command string, args []string, danmu *liveclient.DanmuMessage) {
-	if d.UserPermission && (controller.CurrentMedia != nil) {
-		if controller.CurrentMedia.DanmuUser() != nil && controller.CurrentMedia.DanmuUser().Uid == danmu.User.Uid {
-			controller.PlayNext()
-			return
+// execute skip directly if user has permission, otherwise vote for skip.
+// everyone can run the command so that users without permission can vote.
+func (d *Qiege) execute(ctx *controller.CommandContext) error {
+	user := ctx.User()
+	if d.SelfSkip && (controller.CurrentMedia != nil) {
+		if controller.CurrentMedia.DanmuUser() != nil && controller.CurrentMedia.DanmuUser().Uid == user.Uid {
+			controller.Skip(ctx.Actor())
+			return nil
 		}
 	}
-	if d.PrivilegePermission && danmu.User.Privilege > 0 {
-		controller.PlayNext()
-		return
+	if controller.HasAnyRole(user, d.Roles) {
+		controller.Skip(ctx.Actor())
+		return nil
 	}
-	if d.AdminPermission && danmu.User.Admin {
-		controller.PlayNext()
-		return
+	if d.VoteMode && controller.CurrentMedia != nil && d.addVote(user) {
+		l().Info("skip votes reach the threshold, skip current media")
+		controller.Skip(voteActor)
 	}
+	return nil
 }
 
 func (d *Qiege) Title() string {
@@ -90,16 +141,43 @@ func (d *Qiege) CreatePanel() fyne.CanvasObject {
 	if d.panel != nil {
 		return d.panel
 	}
-	dgPerm := container.NewHBox(
-		widget.NewLabel(i18n.T("plugin.qiege.permission")),
-		widget.NewCheckWithData(i18n.T("plugin.qiege.user"), binding.BindBool(&d.UserPermission)),
-		widget.NewCheckWithData(i18n.T("plugin.qiege.privilege"), binding.BindBool(&d.PrivilegePermission)),
-		widget.NewCheckWithData(i18n.T("plugin.qiege.admin"), binding.BindBool(&d.AdminPermission)),
+	// all config changes are audited
+	auditListener := binding.NewDataListener(d.auditor.Changed)
+	bind := gui.NewConfigBinder(auditListener)
+	dgPerm := container.NewVBox(
+		container.NewBorder(nil, nil,
+			widget.NewLabel(i18n.T("plugin.qiege.permission")), nil,
+			gui.NewRoleCheckGroup(d.Roles, func(roles []string) {
+				d.Roles = roles
+				d.auditor.Changed()
+			})),
+		widget.NewCheckWithData(i18n.T("plugin.qiege.self_skip"), bind.Bool(&d.SelfSkip)),
 	)
+	customCmd := gui.NewConfigBinder(auditListener, binding.NewDataListener(d.updateCommands)).String(&d.CustomCMD)
 	qgShortCut := container.NewBorder(nil, nil,
 		widget.NewLabel(i18n.T("plugin.qiege.custom_cmd")), nil,
-		widget.NewEntryWithData(binding.BindString(&d.CustomCMD)),
+		widget.NewEntryWithData(customCmd),
+	)
+	voteMode := container.NewHBox(
+		widget.NewLabel(i18n.T("plugin.qiege.vote")),
+		widget.NewCheckWithData(i18n.T("plugin.qiege.vote.enable"), bind.Bool(&d.VoteMode)),
+	)
+	voteThreshold := container.NewBorder(nil, nil,
+		widget.NewLabel(i18n.T("plugin.qiege.vote.threshold")), nil,
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&d.VoteThreshold))),
+	)
+	votePercent := container.NewBorder(nil, nil,
+		widget.NewLabel(i18n.T("plugin.qiege.vote.percent")), nil,
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&d.VotePercent))),
+	)
+	voteWindow := container.NewBorder(nil, nil,
+		widget.NewLabel(i18n.T("plugin.qiege.vote.window")), nil,
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&d.VoteWindow))),
+	)
+	activeWindow := container.NewBorder(nil, nil,
+		widget.NewLabel(i18n.T("plugin.qiege.vote.active_window")), nil,
+		widget.NewEntryWithData(binding.IntToString(bind.Int(&d.ActiveWindow))),
 	)
-	d.panel = container.NewVBox(dgPerm, qgShortCut)
+	d.panel = container.NewVBox(dgPerm, qgShortCut, voteMode, voteThreshold, votePercent, voteWindow, activeWindow)
 	return d.panel
 }
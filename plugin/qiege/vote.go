@@ -0,0 +1,126 @@
+package qiege
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/player"
+	"math"
+	"sync"
+	"time"
+)
+
+// skipVote keep track of skip votes for current media and recently active chatters
+type skipVote struct {
+	media *player.Media
+	// skipped is true once votes of media reach the threshold, later votes are ignored
+	skipped  bool
+	voters   map[string]int
+	chatters map[string]int
+	lock     sync.Mutex
+}
+
+func newSkipVote() *skipVote {
+	return &skipVote{
+		voters:   make(map[string]int),
+		chatters: make(map[string]int),
+	}
+}
+
+// chatterRecorder record every danmu sender as an active chatter
+type chatterRecorder struct {
+	vote *skipVote
+}
+
+func (c *chatterRecorder) Execute(danmu *liveclient.DanmuMessage) {
+	c.vote.lock.Lock()
+	c.vote.chatters[danmu.User.Uid] = int(time.Now().Unix())
+	c.vote.lock.Unlock()
+}
+
+func (v *skipVote) reset(media *player.Media) {
+	v.lock.Lock()
+	v.media = media
+	v.skipped = false
+	v.voters = make(map[string]int)
+	v.lock.Unlock()
+}
+
+// activeChatters return number of chatters active in last window seconds
+func (v *skipVote) activeChatters(now int, window int) int {
+	cnt := 0
+	for uid, t := range v.chatters {
+		if now-t > window {
+			delete(v.chatters, uid)
+			continue
+		}
+		cnt++
+	}
+	return cnt
+}
+
+// required return number of votes required to skip current media
+func (d *Qiege) requiredVotes(now int) int {
+	if d.VotePercent <= 0 {
+		if d.VoteThreshold < 1 {
+			return 1
+		}
+		return d.VoteThreshold
+	}
+	active := d.vote.activeChatters(now, d.ActiveWindow)
+	required := int(math.Ceil(float64(active) * float64(d.VotePercent) / 100))
+	if required < 1 {
+		required = 1
+	}
+	return required
+}
+
+// addVote add a skip vote from the user, return true if the votes reach the threshold.
+// It returns true only once for each media, votes arrived before the media is skipped are ignored.
+func (d *Qiege) addVote(user *liveclient.DanmuUser) bool {
+	now := int(time.Now().Unix())
+	d.vote.lock.Lock()
+	if d.vote.media != controller.CurrentMedia {
+		d.vote.media = controller.CurrentMedia
+		d.vote.skipped = false
+		d.vote.voters = make(map[string]int)
+	}
+	if d.vote.skipped {
+		d.vote.lock.Unlock()
+		return false
+	}
+	d.vote.voters[user.Uid] = now
+	votes := 0
+	for uid, t := range d.vote.voters {
+		if now-t > d.VoteWindow {
+			delete(d.vote.voters, uid)
+			continue
+		}
+		votes++
+	}
+	required := d.requiredVotes(now)
+	media := d.vote.media
+	if votes >= required {
+		d.vote.skipped = true
+		d.vote.voters = make(map[string]int)
+	}
+	d.vote.lock.Unlock()
+	l().Infof("%s(%s) vote to skip, %d/%d", user.Username, user.Uid, votes, required)
+	controller.EventHandler.CallA(controller.EventSkipVoteUpdate, controller.SkipVoteUpdateEvent{
+		Media:    media,
+		Votes:    votes,
+		Required: required,
+	})
+	return votes >= required
+}
+
+func (d *Qiege) publishReset(media *player.Media) {
+	d.vote.reset(media)
+	d.vote.lock.Lock()
+	required := d.requiredVotes(int(time.Now().Unix()))
+	d.vote.lock.Unlock()
+	controller.EventHandler.CallA(controller.EventSkipVoteUpdate, controller.SkipVoteUpdateEvent{
+		Media:    media,
+		Votes:    0,
+		Required: required,
+	})
+}
@@ -0,0 +1,28 @@
+package qiege
+
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/liveclient"
+	"AynaLivePlayer/player"
+	"testing"
+)
+
+func TestQiege_AddVote(t *testing.T) {
+	d := NewQiege()
+	d.VoteThreshold = 2
+	controller.CurrentMedia = &player.Media{Title: "a"}
+	if d.addVote(&liveclient.DanmuUser{Uid: "1"}) {
+		t.Fatal("one vote should not skip")
+	}
+	if !d.addVote(&liveclient.DanmuUser{Uid: "2"}) {
+		t.Fatal("votes reach the threshold should skip")
+	}
+	// votes arrived before current media changes should not skip again
+	if d.addVote(&liveclient.DanmuUser{Uid: "3"}) || d.addVote(&liveclient.DanmuUser{Uid: "4"}) {
+		t.Fatal("skipped media should not be skipped again")
+	}
+	controller.CurrentMedia = &player.Media{Title: "b"}
+	if d.addVote(&liveclient.DanmuUser{Uid: "3"}) {
+		t.Fatal("votes should be reset for new media")
+	}
+}
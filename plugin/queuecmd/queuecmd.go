@@ -0,0 +1,246 @@
+package queuecmd
+
+import (
+	"AynaLivePlayer/config"
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/gui"
+	"AynaLivePlayer/i18n"
+	"AynaLivePlayer/logger"
+	"fmt"
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+)
+
+const MODULE_CMD_QUEUE = "CMD.Queue"
+
+func l() *logrus.Entry {
+	return logger.Logger.WithField("Module", MODULE_CMD_QUEUE)
+}
+
+// QueueCmd provide commands for viewers to manage their own requests in user playlist,
+// and for admins to bump or remove a request.
+type QueueCmd struct {
+	CancelCMD         string
+	MySongsCMD        string
+	NowPlayingCMD     string
+	BumpCMD           string
+	RemoveCMD         string
+	ReplyCancel       string
+	ReplyMySongs      string
+	ReplyNoSongs      string
+	ReplyNowPlaying   string
+	ReplyBump         string
+	ReplyRemove       string
+	auditor           *controller.ConfigAuditor
+	cancelCommand     *controller.Command
+	mySongsCommand    *controller.Command
+	nowPlayingCommand *controller.Command
+	bumpCommand       *controller.Command
+	removeCommand     *controller.Command
+	panel             fyne.CanvasObject
+}
+
+func NewQueueCmd() *QueueCmd {
+	return &QueueCmd{
+		CancelCMD:       "cancel",
+		MySongsCMD:      "mysongs",
+		NowPlayingCMD:   "np",
+		BumpCMD:         "bump",
+		RemoveCMD:       "remove",
+		ReplyCancel:     "已取消: {title}",
+		ReplyMySongs:    "{user}: {songs}",
+		ReplyNoSongs:    "{user} 没有点歌",
+		ReplyNowPlaying: "正在播放: {title} - {artist} 点歌人: {requester}",
+		ReplyBump:       "已置顶: {title}",
+		ReplyRemove:     "已删除: {title}",
+	}
+}
+
+func (q *QueueCmd) Name() string {
+	return "QueueCmd"
+}
+
+func (q *QueueCmd) Enable() error {
+	config.LoadConfig(q)
+	q.auditor = controller.NewConfigAuditor(controller.ActorGUI, q)
+	q.cancelCommand = &controller.Command{
+		Name:        "取消点歌",
+		Description: "取消自己最后点的歌",
+		Permission:  controller.PermissionEveryone,
+		Execute:     q.cancel,
+	}
+	q.mySongsCommand = &controller.Command{
+		Name:         "我的点歌",
+		Description:  "查看自己点的歌和位置",
+		Permission:   controller.PermissionEveryone,
+		UserCooldown: 10,
+		Execute:      q.mySongs,
+	}
+	q.nowPlayingCommand = &controller.Command{
+		Name:           "当前歌曲",
+		Description:    "查看正在播放的歌曲和点歌人",
+		Permission:     controller.PermissionEveryone,
+		GlobalCooldown: 5,
+		Execute:        q.nowPlaying,
+	}
+	q.bumpCommand = &controller.Command{
+		Name:        "置顶",
+		Description: "把指定位置的歌曲移到队列最前",
+		Args:        []controller.CommandArg{{Name: "position", Required: true}},
+		Permission:  controller.PermissionAdmin,
+		Execute:     q.bump,
+	}
+	q.removeCommand = &controller.Command{
+		Name:        "删除",
+		Description: "删除指定位置的歌曲",
+		Args:        []controller.CommandArg{{Name: "position", Required: true}},
+		Permission:  controller.PermissionAdmin,
+		Execute:     q.remove,
+	}
+	q.updateCommands()
+	controller.RegisterCommand(q.cancelCommand, q.mySongsCommand, q.nowPlayingCommand, q.bumpCommand, q.removeCommand)
+	gui.AddConfigLayout(q)
+	return nil
+}
+
+func (q *QueueCmd) Disable() error {
+	return nil
+}
+
+// updateCommands apply config to the command specs, it should be called when config changes
+func (q *QueueCmd) updateCommands() {
+	q.cancelCommand.Aliases = []string{q.CancelCMD}
+	q.mySongsCommand.Aliases = []string{q.MySongsCMD}
+	q.nowPlayingCommand.Aliases = []string{q.NowPlayingCMD}
+	q.bumpCommand.Aliases = []string{q.BumpCMD}
+	q.removeCommand.Aliases = []string{q.RemoveCMD}
+}
+
+// position parse position argument which starts from 1, return index in user playlist
+func position(ctx *controller.CommandContext) (int, error) {
+	pos, err := strconv.Atoi(ctx.Arg("position"))
+	if err != nil || pos < 1 {
+		l().Infof("invalid position %s", ctx.Arg("position"))
+		return 0, controller.ErrorCommandArgs
+	}
+	return pos - 1, nil
+}
+
+func (q *QueueCmd) cancel(ctx *controller.CommandContext) error {
+	media := controller.CancelRequest(ctx.User().Uid, ctx.Actor())
+	if media == nil {
+		ctx.ReplyTemplate(q.ReplyNoSongs, nil)
+		return nil
+	}
+	ctx.ReplyTemplate(q.ReplyCancel, map[string]interface{}{"title": media.Title, "artist": media.Artist})
+	return nil
+}
+
+func (q *QueueCmd) mySongs(ctx *controller.CommandContext) error {
+	positions, medias := controller.UserRequests(controller.UserPlaylist, ctx.User().Uid)
+	if len(medias) == 0 {
+		ctx.ReplyTemplate(q.ReplyNoSongs, nil)
+		return nil
+	}
+	songs := make([]string, len(medias))
+	for i, m := range medias {
+		songs[i] = fmt.Sprintf("%d.%s", positions[i]+1, m.Title)
+	}
+	ctx.ReplyTemplate(q.ReplyMySongs, map[string]interface{}{"songs": strings.Join(songs, " "), "count": len(songs)})
+	return nil
+}
+
+func (q *QueueCmd) nowPlaying(ctx *controller.CommandContext) error {
+	media := controller.CurrentMedia
+	if media == nil {
+		return nil
+	}
+	ctx.ReplyTemplate(q.ReplyNowPlaying, map[string]interface{}{
+		"title":     media.Title,
+		"artist":    media.Artist,
+		"requester": media.ToUser().Name,
+	})
+	return nil
+}
+
+func (q *QueueCmd) bump(ctx *controller.CommandContext) error {
+	index, err := position(ctx)
+	if err != nil {
+		return err
+	}
+	if media := controller.BumpRequest(index, ctx.Actor()); media != nil {
+		ctx.ReplyTemplate(q.ReplyBump, map[string]interface{}{"title": media.Title, "artist": media.Artist})
+	}
+	return nil
+}
+
+func (q *QueueCmd) remove(ctx *controller.CommandContext) error {
+	index, err := position(ctx)
+	if err != nil {
+		return err
+	}
+	if media := controller.RemoveRequest(index, ctx.Actor()); media != nil {
+		ctx.ReplyTemplate(q.ReplyRemove, map[string]interface{}{"title": media.Title, "artist": media.Artist})
+	}
+	return nil
+}
+
+func (q *QueueCmd) Title() string {
+	return i18n.T("plugin.queuecmd.title")
+}
+
+func (q *QueueCmd) Description() string {
+	return i18n.T("plugin.queuecmd.description")
+}
+
+func (q *QueueCmd) CreatePanel() fyne.CanvasObject {
+	if q.panel != nil {
+		return q.panel
+	}
+	// command specs are updated when related config changed, all changes are audited
+	auditListener := binding.NewDataListener(q.auditor.Changed)
+	bind := gui.NewConfigBinder(auditListener)
+	bindCmd := gui.NewConfigBinder(auditListener, binding.NewDataListener(q.updateCommands))
+	cmdForm := make([]fyne.CanvasObject, 0)
+	for _, c := range []struct {
+		command *controller.Command
+		value   *string
+	}{
+		{q.cancelCommand, &q.CancelCMD},
+		{q.mySongsCommand, &q.MySongsCMD},
+		{q.nowPlayingCommand, &q.NowPlayingCMD},
+		{q.bumpCommand, &q.BumpCMD},
+		{q.removeCommand, &q.RemoveCMD},
+	} {
+		cmdForm = append(cmdForm, widget.NewLabel(c.command.Name), widget.NewEntryWithData(bindCmd.String(c.value)))
+	}
+	replyForm := make([]fyne.CanvasObject, 0)
+	for _, r := range []struct {
+		key   string
+		value *string
+	}{
+		{"cancel", &q.ReplyCancel},
+		{"my_songs", &q.ReplyMySongs},
+		{"no_songs", &q.ReplyNoSongs},
+		{"now_playing", &q.ReplyNowPlaying},
+		{"bump", &q.ReplyBump},
+		{"remove", &q.ReplyRemove},
+	} {
+		replyForm = append(replyForm,
+			widget.NewLabel(i18n.T("plugin.queuecmd.reply."+r.key)),
+			widget.NewEntryWithData(bind.String(r.value)))
+	}
+	q.panel = container.NewVBox(
+		widget.NewLabel(i18n.T("plugin.queuecmd.custom_cmd")),
+		container.New(layout.NewFormLayout(), cmdForm...),
+		widget.NewLabel(i18n.T("plugin.queuecmd.reply")),
+		container.New(layout.NewFormLayout(), replyForm...),
+	)
+	return q.panel
+}
@@ -8,6 +8,7 @@ import (
 	"AynaLivePlayer/i18n"
 	"AynaLivePlayer/logger"
 	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
@@ -43,6 +44,13 @@ type MediaInfo struct {
 	Album    string
 	Username string
 	Cover    player.Picture
+	Source   string
+	Origin   string
+}
+
+type SkipVoteInfo struct {
+	Votes    int
+	Required int
 }
 
 type OutInfo struct {
@@ -52,6 +60,7 @@ type OutInfo struct {
 	Lyric         string
 	Playlist      []MediaInfo
 	PlaylistCount int
+	SkipVote      SkipVoteInfo
 }
 
 type TextInfo struct {
@@ -201,6 +210,7 @@ func (t *TextInfo) OutputCover() {
 
 func (t *TextInfo) registerHandlers() {
 	controller.MainPlayer.EventHandler.RegisterA(player.EventPlay, "plugin.textinfo.current", func(event *event.Event) {
+		source, origin := provider.GetMediaSource(event.Data.(player.PlayEvent).Media)
 		t.info.Current = MediaInfo{
 			Index:    0,
 			Title:    event.Data.(player.PlayEvent).Media.Title,
@@ -208,6 +218,8 @@ func (t *TextInfo) registerHandlers() {
 			Album:    event.Data.(player.PlayEvent).Media.Album,
 			Cover:    event.Data.(player.PlayEvent).Media.Cover,
 			Username: event.Data.(player.PlayEvent).Media.ToUser().Name,
+			Source:   source,
+			Origin:   origin,
 		}
 		t.RenderTemplates()
 		t.OutputCover()
@@ -258,5 +270,10 @@ func (t *TextInfo) registerHandlers() {
 		t.info.Lyric = lrcLine.Lyric
 		t.RenderTemplates()
 	})
+	controller.EventHandler.RegisterA(controller.EventSkipVoteUpdate, "plugin.textinfo.skipvote", func(event *event.Event) {
+		e := event.Data.(controller.SkipVoteUpdateEvent)
+		t.info.SkipVote = SkipVoteInfo{Votes: e.Votes, Required: e.Required}
+		t.RenderTemplates()
+	})
 
 }
@@ -1,6 +1,9 @@
 package webinfo
 
-import "AynaLivePlayer/player"
+import (
+	"AynaLivePlayer/controller"
+	"AynaLivePlayer/player"
+)
 
 type MediaInfo struct {
 	Index    int
@@ -9,6 +12,21 @@ type MediaInfo struct {
 	Album    string
 	Username string
 	Cover    player.Picture
+	Source   string
+	Origin   string
+}
+
+type SkipVoteInfo struct {
+	Votes    int
+	Required int
+}
+
+// SelectionInfo is search candidates waiting for the requester to choose
+type SelectionInfo struct {
+	Username   string
+	Keyword    string
+	Candidates []MediaInfo
+	Expire     int64
 }
 
 type OutInfo struct {
@@ -17,6 +35,8 @@ type OutInfo struct {
 	TotalTime   int
 	Lyric       string
 	Playlist    []MediaInfo
+	SkipVote    SkipVoteInfo
+	Selection   []SelectionInfo
 }
 
 const (
@@ -25,8 +45,17 @@ const (
 	OutInfoTT = "TotalTime"
 	OutInfoL  = "Lyric"
 	OutInfoPL = "Playlist"
+	OutInfoSV = "SkipVote"
+	OutInfoSL = "Selection"
 )
 
+type StatsInfo struct {
+	TopSongs      []controller.StatEntry
+	TopArtists    []controller.StatEntry
+	TopRequesters []controller.StatEntry
+	Streams       []controller.StreamSummary
+}
+
 type WebsocketData struct {
 	Update string
 	Data   OutInfo
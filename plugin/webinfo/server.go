@@ -2,11 +2,13 @@ package webinfo
 
 import (
 	"AynaLivePlayer/config"
+	"AynaLivePlayer/controller"
 	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/gorilla/websocket"
 	"net/http"
+	"strconv"
 	"sync"
 )
 
@@ -39,13 +41,15 @@ func NewWebInfoServer(port int) *WebInfoServer {
 	server := &WebInfoServer{
 		Store:   newTemplateStore(WebTemplateStorePath),
 		Port:    port,
-		Info:    OutInfo{Playlist: make([]MediaInfo, 0)},
+		Info:    OutInfo{Playlist: make([]MediaInfo, 0), Selection: make([]SelectionInfo, 0)},
 		Clients: map[*Client]int{},
 	}
 	mux := http.NewServeMux()
 	mux.Handle("/", http.FileServer(http.Dir(config.GetAssetPath("webinfo"))))
 	mux.HandleFunc("/ws/info", server.handleInfo)
 	mux.HandleFunc("/api/info", server.getInfo)
+	mux.HandleFunc("/api/stats", server.getStats)
+	mux.HandleFunc("/api/points", server.getPoints)
 	mux.HandleFunc("/api/template/list", server.tmplList)
 	mux.HandleFunc("/api/template/get", server.tmplGet)
 	mux.HandleFunc("/api/template/save", server.tmplSave)
@@ -108,6 +112,7 @@ func (s *WebInfoServer) tmplSave(w http.ResponseWriter, r *http.Request) {
 	}
 	lg.Infof("change template %s", name)
 	s.Store.Modify(name, tmpl)
+	controller.Audit(controller.WebActor(r.RemoteAddr), controller.AuditConfig, "webinfo template %s", name)
 	d, _ := json.Marshal(s.Store.Get(name))
 	_, err := w.Write(d)
 	if err != nil {
@@ -129,6 +134,45 @@ func (s *WebInfoServer) getInfo(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getStats return play statistics, query parameter limit is the size of top lists
+// and since is the unix timestamp of the earliest record.
+func (s *WebInfoServer) getStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil {
+		limit = 10
+	}
+	since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	d, _ := json.Marshal(StatsInfo{
+		TopSongs:      controller.Stats.TopSongs(limit, since),
+		TopArtists:    controller.Stats.TopArtists(limit, since),
+		TopRequesters: controller.Stats.TopRequesters(limit, since),
+		Streams:       controller.Stats.StreamSummaries(since),
+	})
+	_, err = w.Write(d)
+	if err != nil {
+		lg.Warnf("/api/stats error: %s", err)
+		return
+	}
+}
+
+// getPoints return points leaderboard, query parameter limit is the size of leaderboard
+func (s *WebInfoServer) getPoints(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil {
+		limit = 10
+	}
+	d, _ := json.Marshal(controller.Points.Top(limit))
+	_, err = w.Write(d)
+	if err != nil {
+		lg.Warnf("/api/points error: %s", err)
+		return
+	}
+}
+
 func (s *WebInfoServer) handleInfo(w http.ResponseWriter, r *http.Request) {
 	lg.Debug("connection start")
 	conn, err := upgrader.Upgrade(w, r, nil)
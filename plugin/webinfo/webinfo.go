@@ -8,6 +8,7 @@ import (
 	"AynaLivePlayer/i18n"
 	"AynaLivePlayer/logger"
 	"AynaLivePlayer/player"
+	"AynaLivePlayer/provider"
 	"AynaLivePlayer/util"
 	"fmt"
 	"fyne.io/fyne/v2"
@@ -67,6 +68,7 @@ func (w *WebInfo) Disable() error {
 
 func (t *WebInfo) registerHandlers() {
 	controller.MainPlayer.EventHandler.RegisterA(player.EventPlay, "plugin.webinfo.current", func(event *event.Event) {
+		source, origin := provider.GetMediaSource(event.Data.(player.PlayEvent).Media)
 		t.server.Info.Current = MediaInfo{
 			Index:    0,
 			Title:    event.Data.(player.PlayEvent).Media.Title,
@@ -74,6 +76,8 @@ func (t *WebInfo) registerHandlers() {
 			Album:    event.Data.(player.PlayEvent).Media.Album,
 			Cover:    event.Data.(player.PlayEvent).Media.Cover,
 			Username: event.Data.(player.PlayEvent).Media.ToUser().Name,
+			Source:   source,
+			Origin:   origin,
 		}
 		t.server.SendInfo(
 			OutInfoC,
@@ -138,6 +142,40 @@ func (t *WebInfo) registerHandlers() {
 			OutInfo{Lyric: t.server.Info.Lyric},
 		)
 	})
+	controller.EventHandler.RegisterA(controller.EventSkipVoteUpdate, "plugin.webinfo.skipvote", func(event *event.Event) {
+		e := event.Data.(controller.SkipVoteUpdateEvent)
+		t.server.Info.SkipVote = SkipVoteInfo{Votes: e.Votes, Required: e.Required}
+		t.server.SendInfo(
+			OutInfoSV,
+			OutInfo{SkipVote: t.server.Info.SkipVote},
+		)
+	})
+	controller.EventHandler.RegisterA(controller.EventSelectionUpdate, "plugin.webinfo.selection", func(event *event.Event) {
+		e := event.Data.(controller.SelectionUpdateEvent)
+		sl := make([]SelectionInfo, 0, len(e.Selections))
+		for _, s := range e.Selections {
+			candidates := make([]MediaInfo, 0, len(s.Candidates))
+			for index, m := range s.Candidates {
+				candidates = append(candidates, MediaInfo{
+					Index:  index,
+					Title:  m.Title,
+					Artist: m.Artist,
+					Album:  m.Album,
+				})
+			}
+			sl = append(sl, SelectionInfo{
+				Username:   s.User.Username,
+				Keyword:    s.Keyword,
+				Candidates: candidates,
+				Expire:     s.Expire.Unix(),
+			})
+		}
+		t.server.Info.Selection = sl
+		t.server.SendInfo(
+			OutInfoSL,
+			OutInfo{Selection: t.server.Info.Selection},
+		)
+	})
 }
 
 func (w *WebInfo) getServerStatusText() string {
@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrorExternalApi    = errors.New("external api error")
 	ErrorNoSuchProvider = errors.New("not such provider")
+	ErrorNotSupported   = errors.New("not supported by provider")
 )
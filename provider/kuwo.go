@@ -115,10 +115,11 @@ func (k *Kuwo) Search(keyword string) ([]*player.Media, error) {
 	result := make([]*player.Media, 0)
 	gjson.Parse(resp).Get("data.list").ForEach(func(key, value gjson.Result) bool {
 		result = append(result, &player.Media{
-			Title:  html.UnescapeString(value.Get("name").String()),
-			Cover:  player.Picture{Url: value.Get("pic").String()},
-			Artist: value.Get("artist").String(),
-			Album:  value.Get("album").String(),
+			Title:    html.UnescapeString(value.Get("name").String()),
+			Cover:    player.Picture{Url: value.Get("pic").String()},
+			Artist:   value.Get("artist").String(),
+			Album:    value.Get("album").String(),
+			Duration: int(value.Get("duration").Int()),
 			Meta: Meta{
 				Name: k.GetName(),
 				Id:   value.Get("rid").String(),
@@ -142,6 +143,7 @@ func (k *Kuwo) UpdateMedia(media *player.Media) error {
 	media.Cover.Url = jresp.Get("data.pic").String()
 	media.Artist = jresp.Get("data.artist").String()
 	media.Album = jresp.Get("data.album").String()
+	media.Duration = int(jresp.Get("data.duration").Int())
 	return nil
 }
 
@@ -191,10 +193,11 @@ func (k *Kuwo) GetPlaylist(meta Meta) ([]*player.Media, error) {
 			medias = append(
 				medias,
 				&player.Media{
-					Title:  html.UnescapeString(value.Get("name").String()),
-					Artist: value.Get("artist").String(),
-					Cover:  player.Picture{Url: value.Get("pic").String()},
-					Album:  value.Get("album").String(),
+					Title:    html.UnescapeString(value.Get("name").String()),
+					Artist:   value.Get("artist").String(),
+					Cover:    player.Picture{Url: value.Get("pic").String()},
+					Album:    value.Get("album").String(),
+					Duration: int(value.Get("duration").Int()),
 					Meta: Meta{
 						Name: k.GetName(),
 						Id:   value.Get("rid").String(),
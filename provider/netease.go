@@ -123,13 +123,14 @@ func (n *Netease) GetPlaylist(meta Meta) ([]*player.Media, error) {
 		}
 		for i := 0; i < cnt; i++ {
 			medias = append(medias, &player.Media{
-				Title:  result2.Songs[i].Name,
-				Artist: _neteaseGetArtistNames(result2.Songs[i]),
-				Cover:  player.Picture{Url: result2.Songs[i].Al.PicUrl},
-				Album:  result2.Songs[i].Al.Name,
-				Url:    "",
-				Header: nil,
-				User:   nil,
+				Title:    result2.Songs[i].Name,
+				Artist:   _neteaseGetArtistNames(result2.Songs[i]),
+				Cover:    player.Picture{Url: result2.Songs[i].Al.PicUrl},
+				Album:    result2.Songs[i].Al.Name,
+				Duration: result2.Songs[i].Dt / 1000,
+				Url:      "",
+				Header:   nil,
+				User:     nil,
 				Meta: Meta{
 					Name: n.GetName(),
 					Id:   strconv.Itoa(result2.Songs[i].Id),
@@ -161,12 +162,13 @@ func (n *Netease) Search(keyword string) ([]*player.Media, error) {
 			artists = append(artists, a.Name)
 		}
 		medias = append(medias, &player.Media{
-			Title:  song.Name,
-			Artist: strings.Join(artists, ","),
-			Cover:  player.Picture{},
-			Album:  song.Album.Name,
-			Url:    "",
-			Header: nil,
+			Title:    song.Name,
+			Artist:   strings.Join(artists, ","),
+			Cover:    player.Picture{},
+			Album:    song.Album.Name,
+			Duration: song.Duration / 1000,
+			Url:      "",
+			Header:   nil,
 			Meta: Meta{
 				Name: n.GetName(),
 				Id:   strconv.Itoa(song.Id),
@@ -190,6 +192,7 @@ func (n *Netease) UpdateMedia(media *player.Media) error {
 	media.Cover.Url = result.Songs[0].Al.PicUrl
 	media.Album = result.Songs[0].Al.Name
 	media.Artist = _neteaseGetArtistNames(result.Songs[0])
+	media.Duration = result.Songs[0].Dt / 1000
 	return nil
 }
 
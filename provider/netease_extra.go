@@ -1,9 +1,14 @@
 package provider
 
 import (
+	"AynaLivePlayer/player"
+	"AynaLivePlayer/util"
 	"fmt"
 	neteaseApi "github.com/XiaoMengXinX/Music163Api-Go/api"
+	neteaseUtil "github.com/XiaoMengXinX/Music163Api-Go/utils"
+	"github.com/tidwall/gjson"
 	"net/http"
+	"strings"
 )
 
 // Netease other method
@@ -60,3 +65,80 @@ func (n *Netease) Logout() {
 	}
 	return
 }
+
+const (
+	neteaseSimiSongAPI  = "/api/v1/discovery/simiSong"
+	neteaseArtistTopAPI = "/api/artist/top/song"
+)
+
+// _neteaseParseSongs convert songs in api response into medias,
+// both old (artists, album, duration) and new (ar, al, dt) field names are supported.
+func (n *Netease) _neteaseParseSongs(songs gjson.Result) []*player.Media {
+	medias := make([]*player.Media, 0)
+	songs.ForEach(func(key, song gjson.Result) bool {
+		artists := make([]string, 0)
+		ar := song.Get("ar")
+		if !ar.Exists() {
+			ar = song.Get("artists")
+		}
+		ar.ForEach(func(key, value gjson.Result) bool {
+			artists = append(artists, value.Get("name").String())
+			return true
+		})
+		al := song.Get("al")
+		if !al.Exists() {
+			al = song.Get("album")
+		}
+		duration := song.Get("dt").Int()
+		if duration == 0 {
+			duration = song.Get("duration").Int()
+		}
+		medias = append(medias, &player.Media{
+			Title:    song.Get("name").String(),
+			Artist:   strings.Join(artists, ","),
+			Cover:    player.Picture{Url: al.Get("picUrl").String()},
+			Album:    al.Get("name").String(),
+			Duration: int(duration / 1000),
+			Meta: Meta{
+				Name: n.GetName(),
+				Id:   song.Get("id").String(),
+			},
+		})
+		return true
+	})
+	return medias
+}
+
+func (n *Netease) _neteaseRequest(path string, body string) (gjson.Result, error) {
+	resp, _, err := neteaseUtil.ApiRequest(neteaseUtil.EapiOption{
+		Path: path,
+		Url:  "https://music.163.com/eapi" + strings.TrimPrefix(path, "/api"),
+		Json: body,
+	}, n.ReqData)
+	if err != nil || gjson.Get(resp, "code").Int() != 200 {
+		return gjson.Result{}, ErrorExternalApi
+	}
+	return gjson.Parse(resp), nil
+}
+
+// Recommend return similar songs of the media, or top songs of its artist if there is no similar song
+func (n *Netease) Recommend(media *player.Media) ([]*player.Media, error) {
+	id := util.StringToInt(media.Meta.(Meta).Id)
+	result, err := n._neteaseRequest(neteaseSimiSongAPI,
+		fmt.Sprintf(`{"songid":%d,"limit":50,"offset":0}`, id))
+	if err == nil {
+		if medias := n._neteaseParseSongs(result.Get("songs")); len(medias) > 0 {
+			return medias, nil
+		}
+	}
+	detail, err := neteaseApi.GetSongDetail(n.ReqData, []int{id})
+	if err != nil || len(detail.Songs) == 0 || len(detail.Songs[0].Ar) == 0 {
+		return nil, ErrorExternalApi
+	}
+	result, err = n._neteaseRequest(neteaseArtistTopAPI,
+		fmt.Sprintf(`{"id":%d}`, detail.Songs[0].Ar[0].Id))
+	if err != nil {
+		return nil, err
+	}
+	return n._neteaseParseSongs(result.Get("songs")), nil
+}
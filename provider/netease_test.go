@@ -87,3 +87,20 @@ func TestNetease_UpdateMediaLyric(t *testing.T) {
 	fmt.Println(err)
 	fmt.Println(media.Lyric)
 }
+
+func TestNetease_Recommend(t *testing.T) {
+	media := &player.Media{
+		Meta: Meta{
+			Name: NeteaseAPI.GetName(),
+			Id:   "33516503",
+		},
+	}
+	medias, err := NeteaseAPI.Recommend(media)
+	fmt.Println(err)
+	if err != nil {
+		return
+	}
+	for _, m := range medias {
+		fmt.Println(m.Title, m.Artist, m.Meta)
+	}
+}
@@ -15,6 +15,8 @@ func l() *logrus.Entry {
 type Meta struct {
 	Name string
 	Id   string
+	// Origin is the original meta when this media is a fallback from other provider
+	Origin *Meta
 }
 
 type MediaProvider interface {
@@ -28,8 +30,26 @@ type MediaProvider interface {
 	UpdateMediaLyric(media *player.Media) error
 }
 
+// MediaRecommender is implemented by providers which can find medias similar to a given media
+type MediaRecommender interface {
+	Recommend(media *player.Media) ([]*player.Media, error)
+}
+
 var Providers map[string]MediaProvider = make(map[string]MediaProvider)
 
+// GetMediaSource return the provider name of the media,
+// and the original provider name if the media is a fallback from other provider.
+func GetMediaSource(media *player.Media) (source string, origin string) {
+	meta, ok := media.Meta.(Meta)
+	if !ok {
+		return "", ""
+	}
+	if meta.Origin != nil {
+		return meta.Name, meta.Origin.Name
+	}
+	return meta.Name, ""
+}
+
 func GetPlaylist(meta Meta) ([]*player.Media, error) {
 	if v, ok := Providers[meta.Name]; ok {
 		return v.GetPlaylist(meta)
@@ -78,3 +98,19 @@ func UpdateMediaLyric(media *player.Media) error {
 	}
 	return ErrorNoSuchProvider
 }
+
+// Recommend return medias similar to the media using its provider
+func Recommend(media *player.Media) ([]*player.Media, error) {
+	meta, ok := media.Meta.(Meta)
+	if !ok {
+		return nil, ErrorNoSuchProvider
+	}
+	v, ok := Providers[meta.Name]
+	if !ok {
+		return nil, ErrorNoSuchProvider
+	}
+	if r, ok := v.(MediaRecommender); ok {
+		return r.Recommend(media)
+	}
+	return nil, ErrorNotSupported
+}
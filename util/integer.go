@@ -6,3 +6,10 @@ func IntMin(x, y int) int {
 	}
 	return y
 }
+
+func IntMax(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
@@ -1,8 +1,11 @@
 package util
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"io/ioutil"
+	"os"
 )
 
 func MarshalUnescape(v interface{}) (string, error) {
@@ -31,3 +34,55 @@ func MarshalIndentUnescape(v interface{}, prefix, indent string) (string, error)
 	}
 	return buf.String(), nil
 }
+
+// LoadJson read json file and unmarshal it into v
+func LoadJson(filename string, v interface{}) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+// SaveJson marshal v with indent and write it to the file
+func SaveJson(filename string, v interface{}) error {
+	content, err := MarshalIndentUnescape(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, []byte(content), 0666)
+}
+
+// AppendJsonLine marshal v and append it to the file as a single line
+func AppendJsonLine(filename string, v interface{}) error {
+	content, err := MarshalUnescape(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// encoder already ends the json with a newline
+	_, err = f.WriteString(content)
+	return err
+}
+
+// LoadJsonLines read the file line by line and call onLine with each non-empty line
+func LoadJsonLines(filename string, onLine func(line []byte)) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		onLine(scanner.Bytes())
+	}
+	return scanner.Err()
+}
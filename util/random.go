@@ -0,0 +1,28 @@
+package util
+
+import "math/rand"
+
+// WeightedRandomIndex pick a random index with probability proportional to its weight.
+// weight <= 0 will never be picked, return -1 if no index can be picked
+func WeightedRandomIndex(weights []int) int {
+	total := 0
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	if total == 0 {
+		return -1
+	}
+	r := rand.Intn(total)
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return -1
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWeightedRandomIndex(t *testing.T) {
+	counts := make([]int, 4)
+	for i := 0; i < 10000; i++ {
+		index := WeightedRandomIndex([]int{6, 3, 1, 0})
+		if index < 0 || index == 3 {
+			t.Fatalf("unexpected index %d", index)
+		}
+		counts[index]++
+	}
+	fmt.Println(counts)
+	if WeightedRandomIndex([]int{0, -1}) != -1 {
+		t.Fatal("no positive weight should return -1")
+	}
+}
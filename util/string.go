@@ -3,6 +3,8 @@ package util
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 func SliceString(str string, from int, to int) (string, bool) {
@@ -51,3 +53,101 @@ func GetOrDefault(s string, def string) string {
 	}
 	return s
 }
+
+// StringSimilarity return similarity of two strings in range [0, 1],
+// based on levenshtein distance, case and spaces are ignored.
+func StringSimilarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(strings.Join(strings.Fields(a), "")))
+	rb := []rune(strings.ToLower(strings.Join(strings.Fields(b), "")))
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = IntMin(IntMin(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+	return 1 - float64(prev[len(rb)])/float64(maxLen)
+}
+
+var argQuotes = map[rune]rune{'"': '"', '\'': '\'', '“': '”', '‘': '’', '「': '」'}
+
+// SplitArgs split command line into arguments by whitespaces,
+// text quoted by "", ”, “”, ‘’ or 「」 is kept as one argument.
+// quotes only take effect at the beginning of an argument, so words like Don't are kept as is.
+func SplitArgs(s string) []string {
+	args := make([]string, 0)
+	var buf strings.Builder
+	var closing rune
+	quoteStart := -1
+	hasArg := false
+	for i, r := range s {
+		if quoteStart >= 0 {
+			if r == closing {
+				quoteStart = -1
+				continue
+			}
+			buf.WriteRune(r)
+			continue
+		}
+		if c, ok := argQuotes[r]; ok && !hasArg {
+			quoteStart = i
+			closing = c
+			hasArg = true
+			continue
+		}
+		if unicode.IsSpace(r) {
+			if hasArg {
+				args = append(args, buf.String())
+				buf.Reset()
+				hasArg = false
+			}
+			continue
+		}
+		buf.WriteRune(r)
+		hasArg = true
+	}
+	if quoteStart >= 0 {
+		// quote is not closed, keep the rest as normal text
+		return append(args, strings.Fields(s[quoteStart:])...)
+	}
+	if hasArg {
+		args = append(args, buf.String())
+	}
+	return args
+}
+
+// SplitByLength split string into parts which have at most n characters
+func SplitByLength(s string, n int) []string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return []string{s}
+	}
+	parts := make([]string, 0, len(runes)/n+1)
+	for i := 0; i < len(runes); i += n {
+		end := i + n
+		if end > len(runes) {
+			end = len(runes)
+		}
+		parts = append(parts, string(runes[i:end]))
+	}
+	return parts
+}
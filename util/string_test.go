@@ -0,0 +1,52 @@
+package util
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStringSimilarity(t *testing.T) {
+	fmt.Println(StringSimilarity("双截棍", "双截棍"))
+	fmt.Println(StringSimilarity("Hello World", "helloworld"))
+	fmt.Println(StringSimilarity("染", "染 (Live)"))
+	if StringSimilarity("abc", "abc") != 1 {
+		t.Fatal("same string should have similarity 1")
+	}
+	if StringSimilarity("abc", "") != 0 {
+		t.Fatal("empty string should have similarity 0")
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	fmt.Println(SplitArgs("点歌 染 reol"))
+	fmt.Println(SplitArgs("点歌  \"Hello World\"   abc"))
+	fmt.Println(SplitArgs("点歌　“晴天 周杰伦” ''"))
+	args := SplitArgs("点歌 'a b' c")
+	if len(args) != 3 || args[1] != "a b" {
+		t.Fatal("quoted argument should be kept")
+	}
+	if len(SplitArgs("a \"\" b")) != 3 {
+		t.Fatal("empty quoted argument should be kept")
+	}
+	args = SplitArgs("点歌 Don't Stop Me Now")
+	if len(args) != 5 || args[1] != "Don't" {
+		t.Fatal("quote in the middle of argument should be kept")
+	}
+	args = SplitArgs("点歌 \"unclosed quote")
+	if len(args) != 3 || args[1] != "\"unclosed" {
+		t.Fatal("unclosed quote should be kept as normal text")
+	}
+	if len(SplitArgs("   ")) != 0 {
+		t.Fatal("blank string has no argument")
+	}
+}
+
+func TestSplitByLength(t *testing.T) {
+	fmt.Println(SplitByLength("已点歌: 晴天 - 周杰伦 - 第12位", 10))
+	if len(SplitByLength("一二三四五六七", 3)) != 3 {
+		t.Fatal("should be split into 3 parts")
+	}
+	if parts := SplitByLength("abc", 0); len(parts) != 1 || parts[0] != "abc" {
+		t.Fatal("non positive length should not split")
+	}
+}